@@ -1 +1,3 @@
 module github.com/GanLuo96214/goroutine_pool
+
+go 1.19
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/GanLuo96214/goroutine_pool/src/pool_manager"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// client talk to pool_manager.Handler over http or unix domain socket
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// newClient create client for addr, addr is a http url(e.g. http://127.0.0.1:8080/admin)
+// or a unix domain socket(e.g. unix:///run/app.sock), prefix is the path Handler mounted under
+func newClient(addr string, prefix string, timeout time.Duration) (*client, error) {
+	c := &client{httpClient: &http.Client{Timeout: timeout}}
+
+	socket, ok := cutPrefix(addr, "unix://")
+	if !ok {
+		socket, ok = cutPrefix(addr, "unix:")
+	}
+	if ok {
+		if socket == "" {
+			return nil, fmt.Errorf("unix socket path is empty: %q", addr)
+		}
+
+		dialer := net.Dialer{}
+		c.httpClient.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		}
+		// host is ignored when dial unix socket
+		c.baseURL = "http://unix" + strings.TrimRight(prefix, "/")
+		return c, nil
+	}
+
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("addr must be http(s)://host[:port][/prefix] or unix:///path/to.sock: %q", addr)
+	}
+	c.baseURL = strings.TrimRight(addr, "/") + strings.TrimRight(prefix, "/")
+
+	return c, nil
+}
+
+func (c *client) list(ctx context.Context) (infos []pool_manager.PoolInfo, err error) {
+	err = c.do(ctx, http.MethodGet, "/pools", nil, &infos)
+	return infos, err
+}
+
+func (c *client) get(ctx context.Context, name string) (info pool_manager.PoolInfo, err error) {
+	err = c.do(ctx, http.MethodGet, poolPath(name, ""), nil, &info)
+	return info, err
+}
+
+func (c *client) scale(ctx context.Context, name string, count uint64) (info pool_manager.PoolInfo, err error) {
+	body := pool_manager.ExpectRunningCountRequest{Count: count}
+	err = c.do(ctx, http.MethodPut, poolPath(name, "expect_running_count"), body, &info)
+	return info, err
+}
+
+func (c *client) setInterval(ctx context.Context, name string, duration time.Duration) (info pool_manager.PoolInfo, err error) {
+	body := pool_manager.DetectExpectDurationRequest{Duration: duration.String()}
+	err = c.do(ctx, http.MethodPut, poolPath(name, "detect_expect_duration"), body, &info)
+	return info, err
+}
+
+func (c *client) pause(ctx context.Context, name string) (info pool_manager.PoolInfo, err error) {
+	err = c.do(ctx, http.MethodPost, poolPath(name, "pause"), nil, &info)
+	return info, err
+}
+
+func (c *client) resume(ctx context.Context, name string) (info pool_manager.PoolInfo, err error) {
+	err = c.do(ctx, http.MethodPost, poolPath(name, "resume"), nil, &info)
+	return info, err
+}
+
+func (c *client) release(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, poolPath(name, ""), nil, nil)
+}
+
+// poolPath escape name, Handler unescape it after routing, so name can contain "/"
+func poolPath(name string, action string) string {
+	path := "/pools/" + url.PathEscape(name)
+	if action != "" {
+		path += "/" + action
+	}
+	return path
+}
+
+// do send body as json and decode response into v, response of failed request return as error
+func (c *client) do(ctx context.Context, method string, path string, body any, v any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var errResp pool_manager.ErrorResponse
+		if json.NewDecoder(resp.Body).Decode(&errResp) != nil || errResp.Error == "" {
+			return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+		}
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, errResp.Error)
+	}
+
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// cutPrefix is strings.CutPrefix, which need go1.20
+func cutPrefix(s, prefix string) (after string, found bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -0,0 +1,228 @@
+// gpoolctl inspect and control pools in a running process through pool_manager.Handler.
+//
+// the process serve the handler on local http or a unix domain socket, e.g.
+//
+//	listener, _ := net.Listen("unix", "/run/app.sock")
+//	go http.Serve(listener, pool_manager.Handler())
+//
+// then
+//
+//	gpoolctl -addr unix:///run/app.sock list
+//	gpoolctl -addr http://127.0.0.1:8080 -prefix /admin scale worker 20
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/GanLuo96214/goroutine_pool/src/pool_manager"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+)
+
+const usage = `usage: gpoolctl [flags] <command> [args]
+
+commands:
+  list                      list pools
+  get <name>                show a pool
+  scale <name> <n>          set the pool's expect running count
+  set-interval <name> <dur> set the pool's detect expect duration, e.g. 500ms
+  pause <name>              retire every container but keep expect running count
+  resume <name>             bring paused containers back
+  release <name>            shutdown the pool and remove it from manager
+  watch [name]              show pools(or the pool) every -interval until interrupted
+
+flags:
+`
+
+var (
+	errUsage = errors.New("invalid usage")
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run execute the command in args, return exit code
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gpoolctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	var (
+		addr     = flags.String("addr", "http://127.0.0.1:8080", "admin endpoint, http(s)://host[:port] or unix:///path/to.sock")
+		prefix   = flags.String("prefix", "", "path the handler mounted under, e.g. /admin")
+		output   = flags.String("o", outputTable, "output format, table or json")
+		timeout  = flags.Duration("timeout", time.Second*10, "timeout of every request")
+		interval = flags.Duration("interval", time.Second*2, "refresh interval of watch")
+	)
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(stderr, "gpoolctl: unknown output format %q\n", *output)
+		return 2
+	}
+
+	c, err := newClient(*addr, *prefix, *timeout)
+	if err != nil {
+		fmt.Fprintf(stderr, "gpoolctl: %v\n", err)
+		return 2
+	}
+
+	err = execute(ctx, c, flags.Args(), stdout, *output, *interval)
+	if err == errUsage {
+		flags.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "gpoolctl: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+func execute(ctx context.Context, c *client, args []string, stdout io.Writer, output string, interval time.Duration) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "list":
+		if len(args) != 0 {
+			return errUsage
+		}
+		infos, err := c.list(ctx)
+		if err != nil {
+			return err
+		}
+		return printInfos(stdout, output, infos)
+
+	case "get", "pause", "resume":
+		if len(args) != 1 {
+			return errUsage
+		}
+		var (
+			info pool_manager.PoolInfo
+			err  error
+		)
+		switch command {
+		case "get":
+			info, err = c.get(ctx, args[0])
+		case "pause":
+			info, err = c.pause(ctx, args[0])
+		case "resume":
+			info, err = c.resume(ctx, args[0])
+		}
+		if err != nil {
+			return err
+		}
+		return printInfo(stdout, output, info)
+
+	case "scale":
+		if len(args) != 2 {
+			return errUsage
+		}
+		count, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid count %q: %w", args[1], err)
+		}
+		info, err := c.scale(ctx, args[0], count)
+		if err != nil {
+			return err
+		}
+		return printInfo(stdout, output, info)
+
+	case "set-interval":
+		if len(args) != 2 {
+			return errUsage
+		}
+		duration, err := time.ParseDuration(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", args[1], err)
+		}
+		info, err := c.setInterval(ctx, args[0], duration)
+		if err != nil {
+			return err
+		}
+		return printInfo(stdout, output, info)
+
+	case "release":
+		if len(args) != 1 {
+			return errUsage
+		}
+		return c.release(ctx, args[0])
+
+	case "watch":
+		if len(args) > 1 {
+			return errUsage
+		}
+		return watch(ctx, c, args, stdout, output, interval)
+	}
+
+	return errUsage
+}
+
+// watch print pools(or the pool named in args) every interval until ctx done
+func watch(ctx context.Context, c *client, args []string, stdout io.Writer, output string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid interval %s", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var (
+			infos []pool_manager.PoolInfo
+			err   error
+		)
+		if len(args) == 1 {
+			var info pool_manager.PoolInfo
+			info, err = c.get(ctx, args[0])
+			infos = []pool_manager.PoolInfo{info}
+		} else {
+			infos, err = c.list(ctx)
+		}
+		if ctx.Err() != nil {
+			// interrupted
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if output == outputTable {
+			fmt.Fprintf(stdout, "%s\n", time.Now().Format(time.RFC3339))
+		}
+		err = printInfos(stdout, output, infos)
+		if err != nil {
+			return err
+		}
+		if output == outputTable {
+			fmt.Fprintln(stdout)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
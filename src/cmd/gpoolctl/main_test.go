@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"github.com/GanLuo96214/goroutine_pool/src/pool_manager"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	TestExitCodeNotMatch = errors.New("exit code not match")
+	TestOutputNotMatch   = errors.New("output not match")
+)
+
+func newTestManager(t *testing.T) *pool_manager.Manager {
+	m := pool_manager.NewManager()
+
+	p, err := pool.NewTaskPool(2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Add("worker", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = p.Shutdown(context.Background())
+	})
+
+	return m
+}
+
+func runTest(t *testing.T, expectCode int, args ...string) string {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := run(context.Background(), args, stdout, stderr)
+	if code != expectCode {
+		t.Fatal(args, code, stderr.String(), TestExitCodeNotMatch)
+	}
+	return stdout.String()
+}
+
+func TestRun_unix(t *testing.T) {
+
+	socket := filepath.Join(t.TempDir(), "gpoolctl.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: newTestManager(t).Handler()}
+	go server.Serve(listener)
+	defer server.Close()
+
+	addr := "unix://" + socket
+
+	out := runTest(t, 0, "-addr", addr, "list")
+	if !strings.HasPrefix(out, "NAME") || !strings.Contains(out, "worker") {
+		t.Fatal(out, TestOutputNotMatch)
+	}
+
+	var info pool_manager.PoolInfo
+	out = runTest(t, 0, "-addr", addr, "-o", "json", "scale", "worker", "5")
+	if err = json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.ExpectRunningCount != 5 {
+		t.Fatal(out, TestOutputNotMatch)
+	}
+
+	out = runTest(t, 0, "-addr", addr, "-o", "json", "set-interval", "worker", "500ms")
+	if err = json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.DetectExpectDuration != "500ms" {
+		t.Fatal(out, TestOutputNotMatch)
+	}
+
+	out = runTest(t, 0, "-addr", addr, "-o", "json", "pause", "worker")
+	if err = json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatal(err)
+	}
+	if !info.Paused {
+		t.Fatal(out, TestOutputNotMatch)
+	}
+
+	out = runTest(t, 0, "-addr", addr, "-o", "json", "resume", "worker")
+	if err = json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Paused {
+		t.Fatal(out, TestOutputNotMatch)
+	}
+
+	runTest(t, 1, "-addr", addr, "get", "nobody")
+	runTest(t, 1, "-addr", addr, "scale", "worker", "-1")
+	runTest(t, 2, "-addr", addr, "scale", "worker")
+	runTest(t, 2, "-addr", addr, "unknown")
+	runTest(t, 2, "-o", "yaml", "list")
+
+}
+
+func TestRun_http(t *testing.T) {
+
+	server := httptest.NewServer(http.StripPrefix("/admin", newTestManager(t).Handler()))
+	defer server.Close()
+
+	out := runTest(t, 0, "-addr", server.URL, "-prefix", "/admin", "get", "worker")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "worker") {
+		t.Fatal(out, TestOutputNotMatch)
+	}
+
+	// watch until interrupted
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*250)
+	defer cancel()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := run(ctx, []string{"-addr", server.URL + "/admin", "-interval", "100ms", "watch", "worker"}, stdout, stderr)
+	if code != 0 {
+		t.Fatal(code, stderr.String(), TestExitCodeNotMatch)
+	}
+	if strings.Count(stdout.String(), "NAME") < 2 {
+		t.Fatal(stdout.String(), TestOutputNotMatch)
+	}
+
+	runTest(t, 0, "-addr", server.URL+"/admin", "release", "worker")
+	runTest(t, 1, "-addr", server.URL+"/admin", "get", "worker")
+
+}
+
+func TestRun_nameWithSlash(t *testing.T) {
+
+	m := newTestManager(t)
+	p, err := pool.NewTaskPool(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = p.Shutdown(context.Background())
+	})
+	err = m.Add("jobs/nightly batch", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.StripPrefix("/admin", m.Handler()))
+	defer server.Close()
+	addr := server.URL + "/admin"
+
+	var info pool_manager.PoolInfo
+	out := runTest(t, 0, "-addr", addr, "-o", "json", "get", "jobs/nightly batch")
+	if err = json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "jobs/nightly batch" {
+		t.Fatal(out, TestOutputNotMatch)
+	}
+
+	out = runTest(t, 0, "-addr", addr, "-o", "json", "scale", "jobs/nightly batch", "3")
+	if err = json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.ExpectRunningCount != 3 || p.GetExpectRunningCount() != 3 {
+		t.Fatal(out, TestOutputNotMatch)
+	}
+
+	runTest(t, 1, "-addr", addr, "get", "jobs")
+	runTest(t, 0, "-addr", addr, "release", "jobs/nightly batch")
+	runTest(t, 1, "-addr", addr, "get", "jobs/nightly batch")
+
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/GanLuo96214/goroutine_pool/src/pool_manager"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printInfos write infos as table or json array
+func printInfos(w io.Writer, output string, infos []pool_manager.PoolInfo) error {
+	if output == outputJSON {
+		return printJSON(w, infos)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tEXPECT\tRUNNING\tPAUSED\tHEALTH\tINTERVAL\tSTARTED\tEXITED\tPANICKED\tRESTARTED\tQUEUE")
+	for _, info := range infos {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%t\t%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			info.Name,
+			info.ExpectRunningCount,
+			info.NowRunningCount,
+			info.Paused,
+			info.Health,
+			info.DetectExpectDuration,
+			info.StartedCount,
+			info.ExitedCount,
+			info.PanickedCount,
+			info.RestartedCount,
+			formatQueueDepths(info.QueueDepths),
+		)
+	}
+	return tw.Flush()
+}
+
+// printInfo write a pool's info as table or json object
+func printInfo(w io.Writer, output string, info pool_manager.PoolInfo) error {
+	if output == outputJSON {
+		return printJSON(w, info)
+	}
+	return printInfos(w, output, []pool_manager.PoolInfo{info})
+}
+
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// formatQueueDepths is queue depth of every priority split by "/", lowest priority first, "-" if not task pool
+func formatQueueDepths(depths []uint64) string {
+	if len(depths) == 0 {
+		return "-"
+	}
+
+	s := make([]string, len(depths))
+	for i, depth := range depths {
+		s[i] = strconv.FormatUint(depth, 10)
+	}
+	return strings.Join(s, "/")
+}
@@ -0,0 +1,133 @@
+package pool
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backoff delay replacing a container which exited rapid(lived less than ResetAfter) or panicked.
+// every slot(the place a container hold in pool) has its own backoff,
+// the delay is Initial * Multiplier^(failures-1) limited by Max, then shift randomly by Jitter.
+// a container lived ResetAfter or longer reset its slot's backoff, even if it panicked at last.
+type Backoff struct {
+	Initial    time.Duration // first delay, 0 means backoff disabled
+	Max        time.Duration // max delay, 0 means no limit
+	Multiplier float64       // less than 1 means default 2
+	Jitter     float64       // 0 to 1, delay will be in [delay*(1-Jitter), delay*(1+Jitter)]
+	ResetAfter time.Duration // 0 means only panic is failure
+}
+
+// WithBackoff set backoff for replacing containers, default is no backoff.
+func WithBackoff(backoff Backoff) Option {
+	return func(o *options) {
+		o.backoff = backoff
+	}
+}
+
+func (b Backoff) enabled() bool {
+	return b.Initial > 0
+}
+
+func (b Backoff) delay(failures uint64) time.Duration {
+	if !b.enabled() || failures == 0 {
+		return 0
+	}
+
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(failures-1))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+
+	if b.Jitter > 0 {
+		jitter := math.Min(b.Jitter, 1)
+		delay = delay * (1 + jitter*(rand.Float64()*2-1))
+	}
+
+	if delay > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// slot is the place a container hold in pool, replacement container inherit it.
+type slot struct {
+	failures uint64
+	readyAt  time.Time
+}
+
+// slotQueue hold slots whose container exited and waiting for replacement.
+type slotQueue struct {
+	mutex   sync.Mutex
+	pending []*slot
+}
+
+func newSlotQueue() *slotQueue {
+	return new(slotQueue)
+}
+
+// release put back the exited container's slot, replacement will be delayed by backoff.
+func (q *slotQueue) release(s *slot, backoff Backoff, reason ExitReason, lifetime time.Duration) {
+	// lived ResetAfter or longer reset the backoff even if panicked at last, then count this exit.
+	// without ResetAfter only panic is failure, normal exit reset it
+	healthy := lifetime >= backoff.ResetAfter
+	if backoff.ResetAfter == 0 {
+		healthy = reason != ExitPanic
+	}
+	if healthy {
+		s.failures = 0
+	}
+	if reason == ExitPanic || lifetime < backoff.ResetAfter {
+		s.failures++
+	}
+	s.readyAt = time.Now().Add(backoff.delay(s.failures))
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pending = append(q.pending, s)
+}
+
+// take a slot for a new container, need is how many containers pool need now.
+// if no slot can be used now, return nil and how long to wait.
+func (q *slotQueue) take(need uint64) (s *slot, wait time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.pending) == 0 {
+		return new(slot), 0
+	}
+
+	sort.Slice(q.pending, func(i, j int) bool {
+		return q.pending[i].readyAt.Before(q.pending[j].readyAt)
+	})
+
+	// pool scaled down, slots more than need are useless
+	if uint64(len(q.pending)) > need {
+		q.pending = q.pending[:need]
+	}
+	if len(q.pending) == 0 {
+		return nil, 0
+	}
+
+	now := time.Now()
+	if !q.pending[0].readyAt.After(now) {
+		s = q.pending[0]
+		q.pending = q.pending[1:]
+		return s, 0
+	}
+
+	// other needed slots are free
+	if uint64(len(q.pending)) < need {
+		return new(slot), 0
+	}
+
+	return nil, q.pending[0].readyAt.Sub(now)
+}
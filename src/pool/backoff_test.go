@@ -0,0 +1,216 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	TestBackoffDelayNotMatch        = errors.New("backoff delay not match")
+	TestBackoffDelayOutOfJitter     = errors.New("backoff delay out of jitter range")
+	TestSlotQueueTakeNotMatch       = errors.New("slot queue take not match")
+	TestBackoffPanickedCountTooMuch = errors.New("panicked too much, backoff not work")
+)
+
+func TestBackoff_delay(t *testing.T) {
+
+	b := Backoff{
+		Initial:    time.Millisecond * 10,
+		Max:        time.Millisecond * 50,
+		Multiplier: 2,
+	}
+
+	for failures, expect := range []time.Duration{
+		0,
+		time.Millisecond * 10,
+		time.Millisecond * 20,
+		time.Millisecond * 40,
+		time.Millisecond * 50,
+		time.Millisecond * 50,
+	} {
+		if b.delay(uint64(failures)) != expect {
+			t.Fatal(TestBackoffDelayNotMatch)
+		}
+	}
+
+	// disabled
+	if (Backoff{}).delay(10) != 0 {
+		t.Fatal(TestBackoffDelayNotMatch)
+	}
+
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := b.delay(1)
+		if d < time.Millisecond*5 || d > time.Millisecond*15 {
+			t.Fatal(TestBackoffDelayOutOfJitter)
+		}
+	}
+
+}
+
+func TestSlotQueue(t *testing.T) {
+
+	var (
+		q = newSlotQueue()
+		b = Backoff{Initial: time.Hour, ResetAfter: time.Minute}
+	)
+
+	// empty queue give new slot
+	s, wait := q.take(1)
+	if s == nil || wait != 0 {
+		t.Fatal(TestSlotQueueTakeNotMatch)
+	}
+
+	// rapid exit, slot in backoff
+	q.release(s, b, ExitNormal, time.Second)
+	if s.failures != 1 {
+		t.Fatal(TestSlotQueueTakeNotMatch)
+	}
+	s, wait = q.take(1)
+	if s != nil || wait <= 0 {
+		t.Fatal(TestSlotQueueTakeNotMatch)
+	}
+
+	// another slot is free
+	s, wait = q.take(2)
+	if s == nil || s.failures != 0 {
+		t.Fatal(TestSlotQueueTakeNotMatch)
+	}
+
+	// healthy exit reset backoff
+	q.release(s, b, ExitNormal, time.Hour)
+	s, wait = q.take(2)
+	if s == nil || s.failures != 0 || wait != 0 {
+		t.Fatal(TestSlotQueueTakeNotMatch)
+	}
+
+	// scaled down, useless slots dropped
+	s, wait = q.take(0)
+	if s != nil || len(q.pending) != 0 {
+		t.Fatal(TestSlotQueueTakeNotMatch)
+	}
+
+}
+
+func TestSlotQueue_release(t *testing.T) {
+
+	var (
+		q = newSlotQueue()
+		b = Backoff{Initial: time.Second, Multiplier: 2, ResetAfter: time.Minute}
+		s = new(slot)
+	)
+
+	// delay of the slot released now
+	release := func(reason ExitReason, lifetime time.Duration, failures uint64, delay time.Duration) {
+		before := time.Now()
+		q.release(s, b, reason, lifetime)
+		after := time.Now()
+		if s.failures != failures {
+			t.Fatal(s.failures, TestSlotQueueTakeNotMatch)
+		}
+		if s.readyAt.Before(before.Add(delay)) || s.readyAt.After(after.Add(delay)) {
+			t.Fatal(s.readyAt.Sub(before), TestBackoffDelayNotMatch)
+		}
+	}
+
+	// rapid exits and panics double the delay
+	release(ExitNormal, time.Second, 1, time.Second)
+	release(ExitPanic, time.Second, 2, time.Second*2)
+	release(ExitNormal, time.Second, 3, time.Second*4)
+
+	// panicked after a long healthy run, backoff reset then count this panic
+	release(ExitPanic, time.Hour, 1, time.Second)
+	release(ExitPanic, time.Hour, 1, time.Second)
+
+	// healthy exit, no delay
+	release(ExitNormal, time.Hour, 0, 0)
+
+	// without ResetAfter, panics double the delay, normal exit reset it
+	b.ResetAfter = 0
+	release(ExitPanic, time.Hour, 1, time.Second)
+	release(ExitPanic, time.Hour, 2, time.Second*2)
+	release(ExitNormal, 0, 0, 0)
+
+}
+
+func TestWithBackoff(t *testing.T) {
+
+	var (
+		mutex  = sync.Mutex{}
+		starts []time.Time
+		ends   []time.Time
+	)
+
+	p, err := NewPool(
+		1,
+		func(containerIndex uint64) {
+			mutex.Lock()
+			starts = append(starts, time.Now())
+			run := len(starts)
+			mutex.Unlock()
+
+			// the third run is healthy before panic, reset the backoff
+			if run == 3 {
+				time.Sleep(time.Millisecond * 100)
+			}
+
+			mutex.Lock()
+			ends = append(ends, time.Now())
+			mutex.Unlock()
+			panic("boom")
+		},
+		WithPanicHandler(func(containerIndex uint64, recovered any, stack []byte) {}),
+		WithBackoff(Backoff{
+			Initial:    time.Millisecond * 100,
+			Multiplier: 4,
+			ResetAfter: time.Millisecond * 50,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	err = p.SetDetectExpectDuration(time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 10)
+	for {
+		mutex.Lock()
+		n := len(starts)
+		mutex.Unlock()
+		if n >= 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(TestBackoffDelayNotMatch)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mutex.Lock()
+	gaps := []time.Duration{starts[1].Sub(ends[0]), starts[2].Sub(ends[1]), starts[3].Sub(ends[2])}
+	mutex.Unlock()
+
+	// delays are 100ms, 400ms, then reset to 100ms instead of 1.6s
+	for i, delay := range []time.Duration{time.Millisecond * 100, time.Millisecond * 400, time.Millisecond * 100} {
+		if gaps[i] < delay {
+			t.Fatal(gaps, TestBackoffPanickedCountTooMuch)
+		}
+	}
+	if gaps[2] >= time.Millisecond*1600 {
+		t.Fatal(gaps, TestBackoffDelayNotMatch)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
@@ -1,19 +1,16 @@
 package pool
 
 import (
+	"context"
 	"errors"
-	"sync"
+	"runtime/debug"
 	"time"
 )
 
 type buildInLoopPool struct {
-	*Status
+	*supervisor
 
-	containerPrepareNext chan *bool
-
-	reviseContainerRunningCountAsExpectCountMutex sync.Mutex
-
-	runFunc func(containerEnd func(), containerIndex uint64)
+	runFunc func(ctx context.Context, containerEnd func(), containerIndex uint64)
 }
 
 var (
@@ -22,20 +19,13 @@ var (
 
 func newBuildInLoopPool(
 	expectRunningCount uint64,
-	runFunc func(containerEnd func(), containerIndex uint64),
+	runFunc func(ctx context.Context, containerEnd func(), containerIndex uint64),
+	opts ...Option,
 ) (p *buildInLoopPool, err error) {
 
 	p = new(buildInLoopPool)
-	p.Status = new(Status)
-
-	// set default revise  running count
-	err = p.SetDetectExpectDuration(defaultDetectExpectDuration)
-	if err != nil {
-		return nil, err
-	}
 
-	// set expect running  count
-	err = p.SetExpectRunningCount(expectRunningCount)
+	p.supervisor, err = newSupervisor(expectRunningCount, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -46,57 +36,54 @@ func newBuildInLoopPool(
 	}
 
 	p.runFunc = runFunc
+	p.supervisor.containerStart = p.containerStart
 
-	p.containerPrepareNext = make(chan *bool)
-
-	// if GetNowRunningCount() < GetExpectRunningCount() then create containers
-	go p.reviseContainerRunningCountAsExpectCount()
-	// if GetNowRunningCount() > GetExpectRunningCount() then release containers
-	go p.reviseOverflowContainer()
+	// if GetNowRunningCount() < GetExpectRunningCount() then create containers,
+	// if GetNowRunningCount() > GetExpectRunningCount() then cancel surplus containers
+	p.start()
 
 	return p, err
 }
 
-func (p *buildInLoopPool) containerStart(containerBreaker *bool, containerIndex uint64) {
-	p.incrNowRunningCount()
-	defer p.decrNowRunningCount()
+func (p *buildInLoopPool) containerStart(c *container) {
+	defer func() {
+		reason := ExitNormal
+		if recovered := recover(); recovered != nil {
+			reason = ExitPanic
+			p.panicHandler(c.index, recovered, debug.Stack())
+		}
 
-	p.reviseContainerRunningCountAsExpectCountMutex.Unlock()
+		p.containerExit(c, reason)
+	}()
 
 	containerEnd := func() {
-		*containerBreaker = true
+		c.breaker.Store(true)
 	}
 
-	for *containerBreaker == false {
-		p.runFunc(containerEnd, containerIndex)
-		p.containerPrepareNext <- containerBreaker
-	}
+	p.containerStarted(c)
 
-	return
-}
-
-func (p *buildInLoopPool) reviseContainerRunningCountAsExpectCount() {
-	for {
-		p.reviseContainerRunningCountAsExpectCountMutex.Lock()
-
-		if p.GetNowRunningCount() == p.GetExpectRunningCount() || p.GetNowRunningCount() > p.GetExpectRunningCount() {
-			p.reviseContainerRunningCountAsExpectCountMutex.Unlock()
-			time.Sleep(p.GetDetectExpectDuration())
-			continue
+	// every container check its own stop signal after each execution, no rendezvous with others.
+	// container's ctx canceled means the supervisor picked it as surplus or the pool is shutting down.
+	// an iteration's end is next iteration's start, read clock once per iteration,
+	// iteration hooks are observed as a part of iteration
+	start := time.Now()
+	for !c.breaker.Load() && c.ctx.Err() == nil {
+		if p.beforeIteration != nil {
+			p.beforeIteration(c.index)
 		}
 
-		go p.containerStart(p.newContainerBreaker(), p.newContainerIndex())
-	}
-}
+		c.busy.Store(true)
+		p.runFunc(c.ctx, containerEnd, c.index)
+		c.busy.Store(false)
 
-func (p *buildInLoopPool) reviseOverflowContainer() {
-	for {
-
-		containerBreaker := <-p.containerPrepareNext
-
-		if p.GetNowRunningCount() > p.GetExpectRunningCount() {
-			*containerBreaker = true
+		if p.afterIteration != nil {
+			p.afterIteration(c.index)
 		}
 
+		end := time.Now()
+		p.observeRunDuration(end.Sub(start))
+		start = end
 	}
+
+	return
 }
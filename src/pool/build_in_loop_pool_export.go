@@ -1,8 +1,28 @@
 package pool
 
+import "context"
+
 func NewBuildInLoopPool(
 	expectRunningCount uint64,
 	runFunc func(containerEnd func(), containerIndex uint64),
+	opts ...Option,
+) (p *buildInLoopPool, err error) {
+	if runFunc == nil {
+		return newBuildInLoopPool(expectRunningCount, nil, opts...)
+	}
+
+	return newBuildInLoopPool(expectRunningCount, func(ctx context.Context, containerEnd func(), containerIndex uint64) {
+		runFunc(containerEnd, containerIndex)
+	}, opts...)
+}
+
+// NewBuildInLoopPoolWithContext same as NewBuildInLoopPool, but runFunc's ctx will be canceled
+// when the container become surplus(SetExpectRunningCount lower than running count) or the pool shutdown,
+// the container's loop end after runFunc return.
+func NewBuildInLoopPoolWithContext(
+	expectRunningCount uint64,
+	runFunc func(ctx context.Context, containerEnd func(), containerIndex uint64),
+	opts ...Option,
 ) (p *buildInLoopPool, err error) {
-	return newBuildInLoopPool(expectRunningCount, runFunc)
+	return newBuildInLoopPool(expectRunningCount, runFunc, opts...)
 }
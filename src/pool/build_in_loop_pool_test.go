@@ -1,8 +1,11 @@
 package pool
 
 import (
+	"context"
 	"errors"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -11,7 +14,7 @@ func TestNewBuildInLoopPool(t *testing.T) {
 
 	var err error
 
-	_, err = NewBuildInLoopPool(
+	p, err := NewBuildInLoopPool(
 		1,
 		func(containerEnd func(), containerIndex uint64) {
 			time.Sleep(time.Second)
@@ -20,6 +23,7 @@ func TestNewBuildInLoopPool(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	_, err = NewBuildInLoopPool(
 		1,
@@ -42,6 +46,7 @@ func TestBuildInLoopPool_SetDetectExpectDuration(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	var detectExpectDuration time.Duration
 
@@ -85,6 +90,7 @@ func TestBuildInLoopPool_GetDetectExpectDuration(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	if p.GetDetectExpectDuration() != defaultDetectExpectDuration {
 		t.Fatal(TestBuildInLoopPoolGetDetectExpectDurationDefaultValueError)
@@ -128,6 +134,7 @@ func TestBuildInLoopPool_SetExpectRunningCount(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	expectRunningCount = 0
 	err = p.SetExpectRunningCount(expectRunningCount)
@@ -156,6 +163,7 @@ func TestBuildInLoopPool_GetExpectRunningCount(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	expectRunningCount = 0
 	err = p.SetExpectRunningCount(expectRunningCount)
@@ -199,6 +207,7 @@ func TestBuildInLoopPool_GetNowRunningCount(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		shutdownAtCleanup(t, p)
 
 		wg.Wait()
 
@@ -233,6 +242,7 @@ func TestBuildInLoopPool_GetNowRunningCount(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		shutdownAtCleanup(t, p)
 
 		wg.Wait()
 
@@ -280,6 +290,7 @@ func TestBuildInLoopPool_GetNowRunningCount(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		shutdownAtCleanup(t, p)
 
 		//
 		if expectRunningCount != p.GetExpectRunningCount() {
@@ -318,3 +329,414 @@ func TestBuildInLoopPool_GetNowRunningCount(t *testing.T) {
 	}
 
 }
+
+var (
+	TestBuildInLoopPoolShutdownNowRunningCountNotZero = errors.New("now running count should be zero after shutdown")
+)
+
+func TestBuildInLoopPool_Shutdown(t *testing.T) {
+
+	{
+		p, err := NewBuildInLoopPool(
+			10,
+			func(containerEnd func(), containerIndex uint64) {
+				time.Sleep(time.Millisecond)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		err = p.Shutdown(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.GetNowRunningCount() != 0 {
+			t.Fatal(TestBuildInLoopPoolShutdownNowRunningCountNotZero)
+		}
+
+		// shutdown again is safe
+		err = p.Shutdown(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	{
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+
+		p, err := NewBuildInLoopPool(
+			1,
+			func(containerEnd func(), containerIndex uint64) {
+				wg.Done()
+				time.Sleep(time.Hour)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+
+		wg.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+
+		err = p.Shutdown(ctx)
+		if err != context.DeadlineExceeded {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+var (
+	TestNewBuildInLoopPoolWithContextCanceledCountNotMatch = errors.New("canceled containers count not match surplus count")
+)
+
+func TestNewBuildInLoopPoolWithContext(t *testing.T) {
+
+	_, err := NewBuildInLoopPoolWithContext(
+		1,
+		nil,
+	)
+	if err != newBuildInLoopPoolRunFuncIsNil {
+		t.Fatal(err)
+	}
+
+	var (
+		expectRunningCount uint64 = 10
+		enteredCount       uint64 = 0
+		canceledCount      uint64 = 0
+		canceledCountMutex        = sync.Mutex{}
+	)
+
+	p, err := NewBuildInLoopPoolWithContext(
+		expectRunningCount,
+		func(ctx context.Context, containerEnd func(), containerIndex uint64) {
+			canceledCountMutex.Lock()
+			enteredCount++
+			canceledCountMutex.Unlock()
+
+			<-ctx.Done()
+
+			canceledCountMutex.Lock()
+			canceledCount++
+			canceledCountMutex.Unlock()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	err = p.SetDetectExpectDuration(time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a container canceled before entering runFunc would not count
+	for {
+		canceledCountMutex.Lock()
+		entered := enteredCount
+		canceledCountMutex.Unlock()
+		if entered == expectRunningCount {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// scale down, surplus containers' ctx should be canceled and their loop end
+	expectRunningCount = 3
+	err = p.SetExpectRunningCount(expectRunningCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p.GetNowRunningCount() != expectRunningCount {
+		time.Sleep(time.Millisecond)
+	}
+
+	canceledCountMutex.Lock()
+	if canceledCount != 7 {
+		t.Fatal(TestNewBuildInLoopPoolWithContextCanceledCountNotMatch)
+	}
+	canceledCountMutex.Unlock()
+
+	// shutdown cancel the rest
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+var (
+	TestBuildInLoopPoolRestartPolicyExpectCountNotMatch   = errors.New("expect running count not match restart policy")
+	TestBuildInLoopPoolRestartPolicyPanickedCountNotMatch = errors.New("panicked count not match restart policy")
+)
+
+func TestBuildInLoopPool_RestartPolicy(t *testing.T) {
+
+	var (
+		panicHandler = WithPanicHandler(func(containerIndex uint64, recovered any, stack []byte) {})
+	)
+
+	// on failure: containerEnd give up the slot, panicked container replaced at most 1 time
+	{
+		p, err := NewBuildInLoopPool(
+			2,
+			func(containerEnd func(), containerIndex uint64) {
+				if containerIndex%2 == 0 {
+					containerEnd()
+					return
+				}
+				panic("boom")
+			},
+			panicHandler,
+			WithRestartPolicy(RestartOnFailure(1)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+		err = p.SetDetectExpectDuration(time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for p.GetExpectRunningCount() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if p.GetRestartedCount() != 1 {
+			t.Fatal(TestBuildInLoopPoolRestartPolicyPanickedCountNotMatch)
+		}
+	}
+
+	// always: panicked container always replaced
+	{
+		p, err := NewBuildInLoopPool(
+			1,
+			func(containerEnd func(), containerIndex uint64) {
+				panic("boom")
+			},
+			panicHandler,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+		err = p.SetDetectExpectDuration(time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for p.GetPanickedCount() < 5 {
+			time.Sleep(time.Millisecond)
+		}
+		if p.GetExpectRunningCount() != 1 {
+			t.Fatal(TestBuildInLoopPoolRestartPolicyExpectCountNotMatch)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		err = p.Shutdown(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+var (
+	TestBuildInLoopPoolContainerEndNotConverged = errors.New("running count not converged to expect running count")
+)
+
+func TestBuildInLoopPool_containerEndConcurrently(t *testing.T) {
+
+	p, err := NewBuildInLoopPool(
+		50,
+		func(containerEnd func(), containerIndex uint64) {
+			// call containerEnd from other goroutines, sometimes twice
+			if containerIndex%3 == 0 {
+				go containerEnd()
+				go containerEnd()
+			}
+			time.Sleep(time.Millisecond)
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	// overflow reduction while containers ending themselves
+	for count := uint64(50); count >= 10; count -= 5 {
+		err = p.SetExpectRunningCount(count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	deadline := time.Now().Add(time.Second * 10)
+	for p.GetNowRunningCount() != 10 {
+		if time.Now().After(deadline) {
+			t.Fatal(TestBuildInLoopPoolContainerEndNotConverged)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+// iterationsCounter count containers' iterations until b.N reached
+type iterationsCounter struct {
+	measuring  atomic.Bool
+	iterations atomic.Int64
+	target     int64
+	done       chan struct{}
+	end        time.Time // when the last counted iteration finished
+}
+
+func newIterationsCounter(b *testing.B) *iterationsCounter {
+	return &iterationsCounter{target: int64(b.N), done: make(chan struct{})}
+}
+
+// iterate is an iteration of a container, after target reached it block until stop closed,
+// neither spinning nor respawning containers is measured
+func (c *iterationsCounter) iterate(stop <-chan struct{}) {
+	if !c.measuring.Load() {
+		// let other containers start
+		runtime.Gosched()
+		return
+	}
+
+	n := c.iterations.Add(1)
+	if n == c.target {
+		c.end = time.Now()
+		close(c.done)
+	}
+	if n >= c.target {
+		<-stop
+	}
+}
+
+// measure start counting and wait target reached
+func (c *iterationsCounter) measure(b *testing.B) {
+	b.ResetTimer()
+	start := time.Now()
+	c.measuring.Store(true)
+
+	<-c.done
+	b.StopTimer()
+
+	b.ReportMetric(float64(b.N)/c.end.Sub(start).Seconds(), "iterations/s")
+}
+
+func benchmarkBuildInLoopPoolIterations(b *testing.B, containersCount uint64) {
+	counter := newIterationsCounter(b)
+
+	p, err := NewBuildInLoopPoolWithContext(
+		containersCount,
+		func(ctx context.Context, containerEnd func(), containerIndex uint64) {
+			counter.iterate(ctx.Done())
+		},
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	// not measure containers start
+	for p.GetNowRunningCount() != containersCount {
+		time.Sleep(time.Millisecond)
+	}
+
+	counter.measure(b)
+
+	_ = p.Shutdown(context.Background())
+}
+
+// benchmarkRendezvousIterations is how build in loop pool worked before, compare with containers check themselves:
+// after every iteration, every container send on an unbuffered channel,
+// a single goroutine receive it and end the container if running count overflow.
+func benchmarkRendezvousIterations(b *testing.B, containersCount uint64) {
+	var (
+		counter       = newIterationsCounter(b)
+		prepareNext   = make(chan *atomic.Bool)
+		stop          = make(chan struct{})
+		wg            = sync.WaitGroup{}
+		nowRunning    atomic.Uint64
+		expectRunning atomic.Uint64
+	)
+	expectRunning.Store(containersCount)
+
+	// reviseOverflowContainer
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case breaker := <-prepareNext:
+				if nowRunning.Load() > expectRunning.Load() {
+					breaker.Store(true)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for i := uint64(0); i < containersCount; i++ {
+		wg.Add(1)
+		nowRunning.Add(1)
+		go func() {
+			defer wg.Done()
+			defer nowRunning.Add(^uint64(0))
+
+			breaker := new(atomic.Bool)
+			for !breaker.Load() {
+				counter.iterate(stop)
+				select {
+				case prepareNext <- breaker:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	counter.measure(b)
+
+	close(stop)
+	wg.Wait()
+}
+
+// compare with the rendezvous design side by side, e.g. go test -run none -bench iterations ./src/pool
+func BenchmarkBuildInLoopPool_iterations(b *testing.B) {
+	for _, c := range []struct {
+		name            string
+		containersCount uint64
+	}{
+		{"1", 1},
+		{"100", 100},
+		{"10k", 10000},
+	} {
+		b.Run(c.name+"/self_check", func(b *testing.B) {
+			benchmarkBuildInLoopPoolIterations(b, c.containersCount)
+		})
+		b.Run(c.name+"/rendezvous", func(b *testing.B) {
+			benchmarkRendezvousIterations(b, c.containersCount)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package pool
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type container struct {
+	index     uint64
+	slot      *slot
+	startedAt time.Time
+
+	// ctx will be canceled when the container become surplus or the pool shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// retired guarded by containerRegistry.mutex
+	retired bool
+
+	// breaker is set by containerEnd of build in loop pool, safe to call from any goroutine
+	breaker atomic.Bool
+
+	// busy is set while the container executing function(a task for task pool), for ScaleDownIdleFirst
+	busy atomic.Bool
+
+	// state is created by initContainerState of stateful pool, only accessed on the container's goroutine
+	state any
+}
+
+type containerContextKey struct{}
+
+// containerFromContext get the container from its ctx, nil if ctx is not a container's ctx
+func containerFromContext(ctx context.Context) *container {
+	c, _ := ctx.Value(containerContextKey{}).(*container)
+	return c
+}
+
+// ScaleDownPolicy decide which containers retire first when the pool scale down.
+type ScaleDownPolicy int
+
+const (
+	ScaleDownNewestFirst ScaleDownPolicy = iota // the latest started containers retire first, it is the default
+	ScaleDownOldestFirst                        // the earliest started containers retire first
+	ScaleDownIdleFirst                          // containers not executing function retire first, then the newest
+)
+
+// WithScaleDownPolicy set which containers retire first when the pool scale down, default is ScaleDownNewestFirst.
+func WithScaleDownPolicy(policy ScaleDownPolicy) Option {
+	return func(o *options) {
+		o.scaleDownPolicy = policy
+	}
+}
+
+// retireBefore report whether a should retire before b by policy
+func (policy ScaleDownPolicy) retireBefore(a, b *container) bool {
+	if policy == ScaleDownIdleFirst {
+		if aIdle, bIdle := !a.busy.Load(), !b.busy.Load(); aIdle != bIdle {
+			return aIdle
+		}
+	}
+
+	newer := a.startedAt.After(b.startedAt) || a.startedAt.Equal(b.startedAt) && a.index > b.index
+	if policy == ScaleDownOldestFirst {
+		return !newer
+	}
+	return newer
+}
+
+// containerRegistry records running containers, so the pool can pick surplus containers and cancel them.
+type containerRegistry struct {
+	mutex       sync.Mutex
+	containers  map[uint64]*container
+	activeCount uint64 // containers not retired
+}
+
+func newContainerRegistry() *containerRegistry {
+	r := new(containerRegistry)
+	r.containers = make(map[uint64]*container)
+	return r
+}
+
+func (r *containerRegistry) add(parent context.Context, containerIndex uint64, s *slot) *container {
+	c := &container{index: containerIndex, slot: s, startedAt: time.Now()}
+	c.ctx, c.cancel = context.WithCancel(context.WithValue(parent, containerContextKey{}, c))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.containers[containerIndex] = c
+	r.activeCount++
+
+	return c
+}
+
+// remove c from registry, report whether c was retired
+func (r *containerRegistry) remove(c *container) (retired bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	c.cancel()
+
+	if _, ok := r.containers[c.index]; !ok {
+		return c.retired
+	}
+	delete(r.containers, c.index)
+	if !c.retired {
+		r.activeCount--
+	}
+
+	return c.retired
+}
+
+func (r *containerRegistry) getActiveCount() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.activeCount
+}
+
+// must hold r.mutex
+func (r *containerRegistry) retire(c *container) {
+	if c.retired {
+		return
+	}
+	c.retired = true
+	r.activeCount--
+	c.cancel()
+}
+
+// retireSurplus retire containers by policy until active containers count <= expectRunningCount
+func (r *containerRegistry) retireSurplus(expectRunningCount uint64, policy ScaleDownPolicy) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.activeCount <= expectRunningCount {
+		return
+	}
+
+	active := make([]*container, 0, r.activeCount)
+	for _, c := range r.containers {
+		if !c.retired {
+			active = append(active, c)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return policy.retireBefore(active[i], active[j])
+	})
+
+	for _, c := range active[:r.activeCount-expectRunningCount] {
+		r.retire(c)
+	}
+}
@@ -0,0 +1,144 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	TestScaleDownPolicyRetiredNotMatch = errors.New("retired containers not match scale down policy")
+)
+
+func TestWithScaleDownPolicy(t *testing.T) {
+
+	for policy, expectRetired := range map[ScaleDownPolicy][]uint64{
+		ScaleDownNewestFirst: {4, 5},
+		ScaleDownOldestFirst: {1, 2},
+	} {
+		var (
+			retired      []uint64
+			retiredMutex = sync.Mutex{}
+			started      = sync.WaitGroup{}
+		)
+		started.Add(5)
+
+		p, err := NewBuildInLoopPoolWithContext(
+			5,
+			func(ctx context.Context, containerEnd func(), containerIndex uint64) {
+				started.Done()
+				<-ctx.Done()
+
+				retiredMutex.Lock()
+				retired = append(retired, containerIndex)
+				retiredMutex.Unlock()
+			},
+			WithScaleDownPolicy(policy),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+		// retired before function called would not be recorded
+		started.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		err = p.SetExpectRunningCountAndWait(ctx, 3)
+		cancel()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		retiredMutex.Lock()
+		sort.Slice(retired, func(i, j int) bool { return retired[i] < retired[j] })
+		if len(retired) != 2 || retired[0] != expectRetired[0] || retired[1] != expectRetired[1] {
+			t.Fatal(policy, retired, TestScaleDownPolicyRetiredNotMatch)
+		}
+		retiredMutex.Unlock()
+
+		_ = p.Shutdown(context.Background())
+	}
+
+}
+
+func TestWithScaleDownPolicy_idleFirst(t *testing.T) {
+
+	p, err := NewTaskPool(3, 10, WithScaleDownPolicy(ScaleDownIdleFirst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	for p.GetNowRunningCount() != 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var (
+		started = make(chan struct{})
+		release = make(chan struct{})
+		done    = make(chan struct{})
+	)
+	err = p.Submit(func() {
+		close(started)
+		<-release
+		close(done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// idle containers retired, the busy one keep running its task
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.SetExpectRunningCountAndWait(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal(TestScaleDownPolicyRetiredNotMatch)
+	}
+
+	if p.GetNowRunningCount() != 1 {
+		t.Fatal(TestScaleDownPolicyRetiredNotMatch)
+	}
+
+	_ = p.Shutdown(context.Background())
+
+}
+
+func TestSupervisor_SetExpectRunningCountAndWait(t *testing.T) {
+
+	p, err := NewPool(
+		1,
+		func(containerIndex uint64) {
+			time.Sleep(time.Hour)
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.SetExpectRunningCountAndWait(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// function ignore ctx, never reach
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	err = p.SetExpectRunningCountAndWait(ctx, 0)
+	if err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+}
@@ -0,0 +1,93 @@
+package pool
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+type delayedTask struct {
+	at       time.Time
+	sequence uint64 // same at, first submitted first released
+	priority int
+	task     task
+}
+
+// delayedTaskHeap implement heap.Interface, the earliest task on top
+type delayedTaskHeap []*delayedTask
+
+func (h delayedTaskHeap) Len() int { return len(h) }
+func (h delayedTaskHeap) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].sequence < h[j].sequence
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h delayedTaskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *delayedTaskHeap) Push(x any)   { *h = append(*h, x.(*delayedTask)) }
+func (h *delayedTaskHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return x
+}
+
+// delayedTasks hold tasks until they are due, wake notify the releaser an earlier task added.
+type delayedTasks struct {
+	mutex    sync.Mutex
+	heap     delayedTaskHeap
+	sequence uint64
+	wake     chan struct{}
+}
+
+func newDelayedTasks() *delayedTasks {
+	d := new(delayedTasks)
+	d.wake = make(chan struct{}, 1)
+	return d
+}
+
+func (d *delayedTasks) add(at time.Time, priority int, t task) {
+	d.mutex.Lock()
+	d.sequence++
+	heap.Push(&d.heap, &delayedTask{at: at, sequence: d.sequence, priority: priority, task: t})
+	d.mutex.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// popDue pop a due task, if no task due return nil and how long to wait(0 means no task)
+func (d *delayedTasks) popDue(now time.Time) (dt *delayedTask, wait time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if len(d.heap) == 0 {
+		return nil, 0
+	}
+
+	if d.heap[0].at.After(now) {
+		return nil, d.heap[0].at.Sub(now)
+	}
+
+	return heap.Pop(&d.heap).(*delayedTask), 0
+}
+
+// clear remove all tasks, return how many removed
+func (d *delayedTasks) clear() int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	n := len(d.heap)
+	d.heap = nil
+	return n
+}
+
+func (d *delayedTasks) length() uint64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return uint64(len(d.heap))
+}
@@ -0,0 +1,48 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	TestDelayedTasksOrderNotMatch = errors.New("delayed tasks release order not match")
+)
+
+func TestDelayedTasks_popDue(t *testing.T) {
+
+	var (
+		d   = newDelayedTasks()
+		now = time.Now()
+	)
+
+	d.add(now.Add(time.Second*2), 2, nil)
+	d.add(now.Add(time.Second), 1, nil)
+	d.add(now.Add(time.Second), 0, nil)
+	d.add(now.Add(time.Hour), 3, nil)
+
+	// nothing due
+	dt, wait := d.popDue(now)
+	if dt != nil || wait != time.Second {
+		t.Fatal(TestDelayedTasksOrderNotMatch)
+	}
+
+	// earliest first, same time first added first
+	for _, priority := range []int{1, 0, 2} {
+		dt, _ = d.popDue(now.Add(time.Second * 2))
+		if dt == nil || dt.priority != priority {
+			t.Fatal(TestDelayedTasksOrderNotMatch)
+		}
+	}
+
+	if d.length() != 1 || d.clear() != 1 || d.length() != 0 {
+		t.Fatal(TestDelayedTasksOrderNotMatch)
+	}
+
+	dt, wait = d.popDue(now)
+	if dt != nil || wait != 0 {
+		t.Fatal(TestDelayedTasksOrderNotMatch)
+	}
+
+}
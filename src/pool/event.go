@@ -0,0 +1,253 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType is what happened in a pool.
+type EventType int
+
+const (
+	EventContainerStarted  EventType = iota // the supervisor started a container
+	EventContainerExited                    // a container exited, Reason is why
+	EventContainerPanicked                  // a container's function panicked, followed by EventContainerExited
+	EventExpectChanged                      // expect running count changed from From to To
+	EventScaledUp                           // the supervisor started scaling up from From to To containers
+	EventScaledDown                         // the supervisor started scaling down from From to To containers, include pause
+	EventPoolReleased                       // pool_manager released the pool
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventContainerStarted:
+		return "ContainerStarted"
+	case EventContainerExited:
+		return "ContainerExited"
+	case EventContainerPanicked:
+		return "ContainerPanicked"
+	case EventExpectChanged:
+		return "ExpectChanged"
+	case EventScaledUp:
+		return "ScaledUp"
+	case EventScaledDown:
+		return "ScaledDown"
+	case EventPoolReleased:
+		return "PoolReleased"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event is a lifecycle event of a pool.
+type Event struct {
+	Type EventType
+	Time time.Time // when it happened
+
+	Pool string // the pool's name, only set by pool_manager
+
+	ContainerIndex uint64     // container events only
+	StartedAt      time.Time  // container events only, when the container started
+	Reason         ExitReason // EventContainerExited only
+
+	From uint64 // EventExpectChanged, EventScaledUp and EventScaledDown only
+	To   uint64
+}
+
+// SlowSubscriberPolicy decide what to do when a subscriber's channel is full, publisher never block.
+type SlowSubscriberPolicy int
+
+const (
+	DropNewest SlowSubscriberPolicy = iota // drop the event not delivered yet, it is the default
+	DropOldest                             // drop the oldest event in channel to make room
+	Buffer                                 // keep every event in an unbounded buffer until received
+)
+
+type subscribeOptions struct {
+	bufferSize int
+	policy     SlowSubscriberPolicy
+}
+
+// SubscribeOption configure a subscriber.
+type SubscribeOption func(*subscribeOptions)
+
+// WithSubscriberBufferSize set the subscriber channel's capacity, default is 64, size less than 1 is ignored.
+func WithSubscriberBufferSize(size int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if size < 1 {
+			return
+		}
+		o.bufferSize = size
+	}
+}
+
+// WithSlowSubscriberPolicy set what to do when the subscriber's channel is full, default is DropNewest.
+func WithSlowSubscriberPolicy(policy SlowSubscriberPolicy) SubscribeOption {
+	return func(o *subscribeOptions) {
+		o.policy = policy
+	}
+}
+
+type subscriber struct {
+	events chan Event
+	policy SlowSubscriberPolicy
+	fn     func(Event) // SubscribeFunc only
+
+	// DropOldest make room and send atomically, Buffer guard pending
+	mutex   sync.Mutex
+	pending []Event
+	wake    chan struct{}
+	done    chan struct{}
+}
+
+func (s *subscriber) send(e Event) {
+	if s.fn != nil {
+		s.fn(e)
+		return
+	}
+
+	switch s.policy {
+	case DropOldest:
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for {
+			select {
+			case s.events <- e:
+				return
+			default:
+			}
+			select {
+			case <-s.events:
+			default:
+			}
+		}
+	case Buffer:
+		s.mutex.Lock()
+		s.pending = append(s.pending, e)
+		s.mutex.Unlock()
+		notify(s.wake)
+	default:
+		select {
+		case s.events <- e:
+		default:
+		}
+	}
+}
+
+// pump deliver pending events for Buffer policy until canceled, then close the channel
+func (s *subscriber) pump() {
+	defer close(s.events)
+
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+
+		s.mutex.Lock()
+		pending := s.pending
+		s.pending = nil
+		s.mutex.Unlock()
+
+		for _, e := range pending {
+			select {
+			case s.events <- e:
+			case <-s.done:
+				return
+			}
+		}
+	}
+}
+
+// EventBroker deliver published events to subscribers without block, zero value is ready to use.
+type EventBroker struct {
+	mutex           sync.RWMutex
+	subscribers     map[*subscriber]struct{}
+	subscriberCount atomic.Int64
+}
+
+// Subscribe return a channel receive events published after, cancel stop delivering and close the channel.
+// a slow subscriber lose events or buffer them by its SlowSubscriberPolicy, it never block publisher.
+func (b *EventBroker) Subscribe(opts ...SubscribeOption) (events <-chan Event, cancel func()) {
+	o := subscribeOptions{bufferSize: 64}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	s := &subscriber{
+		events: make(chan Event, o.bufferSize),
+		policy: o.policy,
+	}
+	if s.policy == Buffer {
+		s.wake = make(chan struct{}, 1)
+		s.done = make(chan struct{})
+		go s.pump()
+	}
+
+	return s.events, b.add(s, func() {
+		if s.policy == Buffer {
+			close(s.done)
+			return
+		}
+		close(s.events)
+	})
+}
+
+// SubscribeFunc call fn with every event published after on the publisher's goroutine,
+// fn must return quickly. cancel stop calling fn.
+func (b *EventBroker) SubscribeFunc(fn func(Event)) (cancel func()) {
+	return b.add(&subscriber{fn: fn}, func() {})
+}
+
+func (b *EventBroker) add(s *subscriber, closeFunc func()) (cancel func()) {
+	b.mutex.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[*subscriber]struct{})
+	}
+	b.subscribers[s] = struct{}{}
+	b.subscriberCount.Add(1)
+	b.mutex.Unlock()
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			// no publish is sending to s after removed
+			b.mutex.Lock()
+			delete(b.subscribers, s)
+			b.subscriberCount.Add(-1)
+			b.mutex.Unlock()
+
+			closeFunc()
+		})
+	}
+}
+
+// Publish deliver e to every subscriber, e.Time is set to now if zero.
+func (b *EventBroker) Publish(e Event) {
+	// nobody listening, not even read the clock
+	if b.subscriberCount.Load() == 0 {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for s := range b.subscribers {
+		s.send(e)
+	}
+}
+
+// Subscribe return a channel receive the pool's events, see EventBroker.Subscribe.
+func (s *Status) Subscribe(opts ...SubscribeOption) (events <-chan Event, cancel func()) {
+	return s.events.Subscribe(opts...)
+}
+
+// SubscribeFunc call fn with the pool's events, see EventBroker.SubscribeFunc.
+func (s *Status) SubscribeFunc(fn func(Event)) (cancel func()) {
+	return s.events.SubscribeFunc(fn)
+}
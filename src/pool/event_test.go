@@ -0,0 +1,177 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	TestEventNotReceived      = errors.New("event not received")
+	TestEventNotMatch         = errors.New("event not match")
+	TestEventChannelNotClosed = errors.New("event channel not closed after cancel")
+)
+
+// waitEvent receive events until one match t, other events are skipped
+func waitEvent(t *testing.T, events <-chan Event, eventType EventType) Event {
+	timeout := time.After(time.Second * 5)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal(eventType, TestEventNotReceived)
+			}
+			if e.Type == eventType {
+				return e
+			}
+		case <-timeout:
+			t.Fatal(eventType, TestEventNotReceived)
+		}
+	}
+}
+
+func TestStatus_Subscribe(t *testing.T) {
+
+	p, err := NewPoolWithContext(
+		0,
+		func(ctx context.Context, containerIndex uint64) {
+			if containerIndex == 2 {
+				panic("boom")
+			}
+			<-ctx.Done()
+		},
+		WithPanicHandler(func(containerIndex uint64, recovered any, stack []byte) {}),
+		WithRestartPolicy(RestartNever),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	events, cancel := p.Subscribe(WithSlowSubscriberPolicy(Buffer))
+	// scaling events published by the supervisor, order with others is not determined
+	scaleEvents, cancelScale := p.Subscribe(WithSlowSubscriberPolicy(Buffer))
+	defer cancelScale()
+
+	err = p.SetExpectRunningCount(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := waitEvent(t, events, EventExpectChanged)
+	if e.From != 0 || e.To != 2 || e.Time.IsZero() {
+		t.Fatal(e, TestEventNotMatch)
+	}
+	e = waitEvent(t, scaleEvents, EventScaledUp)
+	if e.From != 0 || e.To != 2 {
+		t.Fatal(e, TestEventNotMatch)
+	}
+	e = waitEvent(t, events, EventContainerStarted)
+	if e.ContainerIndex != 1 || e.StartedAt.IsZero() {
+		t.Fatal(e, TestEventNotMatch)
+	}
+
+	// container 2 panicked and not restarted, expect running count decrement before it exited
+	e = waitEvent(t, events, EventContainerPanicked)
+	if e.ContainerIndex != 2 {
+		t.Fatal(e, TestEventNotMatch)
+	}
+	e = waitEvent(t, events, EventExpectChanged)
+	if e.From != 2 || e.To != 1 {
+		t.Fatal(e, TestEventNotMatch)
+	}
+	e = waitEvent(t, events, EventContainerExited)
+	if e.ContainerIndex != 2 || e.Reason != ExitPanic {
+		t.Fatal(e, TestEventNotMatch)
+	}
+
+	e = waitEvent(t, scaleEvents, EventScaledDown)
+	if e.From != 2 || e.To != 1 {
+		t.Fatal(e, TestEventNotMatch)
+	}
+
+	p.Pause()
+	e = waitEvent(t, scaleEvents, EventScaledDown)
+	if e.From != 1 || e.To != 0 {
+		t.Fatal(e, TestEventNotMatch)
+	}
+	e = waitEvent(t, events, EventContainerExited)
+	if e.ContainerIndex != 1 || e.Reason != ExitRetired {
+		t.Fatal(e, TestEventNotMatch)
+	}
+
+	cancel()
+	cancel() // safe to call again
+	for range events {
+	}
+
+	_ = p.Shutdown(context.Background())
+
+}
+
+func TestEventBroker_slowSubscriber(t *testing.T) {
+
+	var b EventBroker
+
+	dropNewest, cancelDropNewest := b.Subscribe(WithSubscriberBufferSize(2))
+	dropOldest, cancelDropOldest := b.Subscribe(WithSubscriberBufferSize(2), WithSlowSubscriberPolicy(DropOldest))
+	buffer, cancelBuffer := b.Subscribe(WithSubscriberBufferSize(2), WithSlowSubscriberPolicy(Buffer))
+
+	var called uint64
+	cancelFunc := b.SubscribeFunc(func(e Event) {
+		called++
+	})
+
+	// nobody receive, publish never block
+	for i := uint64(1); i <= 5; i++ {
+		b.Publish(Event{Type: EventExpectChanged, To: i})
+	}
+	cancelFunc()
+	b.Publish(Event{Type: EventExpectChanged, To: 6})
+	if called != 5 {
+		t.Fatal(called, TestEventNotMatch)
+	}
+
+	receive := func(events <-chan Event, count int) (to []uint64) {
+		for i := 0; i < count; i++ {
+			select {
+			case e := <-events:
+				to = append(to, e.To)
+			case <-time.After(time.Second * 5):
+				t.Fatal(TestEventNotReceived)
+			}
+		}
+		return to
+	}
+
+	if to := receive(dropNewest, 2); to[0] != 1 || to[1] != 2 {
+		t.Fatal(to, TestEventNotMatch)
+	}
+	if to := receive(dropOldest, 2); to[0] != 5 || to[1] != 6 {
+		t.Fatal(to, TestEventNotMatch)
+	}
+	if to := receive(buffer, 6); to[0] != 1 || to[5] != 6 {
+		t.Fatal(to, TestEventNotMatch)
+	}
+
+	for _, c := range []struct {
+		events <-chan Event
+		cancel func()
+	}{
+		{dropNewest, cancelDropNewest},
+		{dropOldest, cancelDropOldest},
+		{buffer, cancelBuffer},
+	} {
+		c.cancel()
+		select {
+		case _, ok := <-c.events:
+			if ok {
+				t.Fatal(TestEventChannelNotClosed)
+			}
+		case <-time.After(time.Second * 5):
+			t.Fatal(TestEventChannelNotClosed)
+		}
+	}
+
+}
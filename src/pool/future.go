@@ -0,0 +1,151 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrTaskPanicked = errors.New("task panicked")
+)
+
+type futureState int
+
+const (
+	futurePending futureState = iota // queued, not started
+	futureRunning
+	futureDone
+)
+
+// Future is the result of a task submitted by SubmitFunc.
+type Future[T any] struct {
+	done chan struct{}
+
+	value          T
+	err            error
+	containerIndex uint64
+
+	mutex     sync.Mutex
+	state     futureState
+	cancelRun context.CancelFunc // cancel the running task's ctx
+}
+
+func newFuture[T any]() *Future[T] {
+	f := new(Future[T])
+	f.done = make(chan struct{})
+	return f
+}
+
+// SubmitFunc submit fn to task pool, fn's ctx will be canceled when Future.Cancel called,
+// the container become surplus or the pool shutdown.
+// if submit failed(e.g. ErrQueueFull, ErrPoolClosed), the future is done with the error,
+// so is a queued task dropped by Shutdown, with ErrPoolClosed.
+func SubmitFunc[T any](p *taskPool, fn func(ctx context.Context) (T, error)) *Future[T] {
+	f := newFuture[T]()
+
+	if fn == nil {
+		f.drop(submitTaskIsNil)
+		return f
+	}
+
+	err := p.submit(context.Background(), 0, func(ctx context.Context, containerIndex uint64) {
+		f.run(ctx, containerIndex, fn)
+	}, f.drop)
+	if err != nil {
+		f.drop(err)
+	}
+
+	return f
+}
+
+func (f *Future[T]) run(containerCtx context.Context, containerIndex uint64, fn func(ctx context.Context) (T, error)) {
+	var zero T
+
+	f.mutex.Lock()
+	if f.state != futurePending {
+		// canceled before started
+		f.mutex.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(containerCtx)
+	f.state = futureRunning
+	f.cancelRun = cancel
+	f.mutex.Unlock()
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			f.complete(zero, containerIndex, fmt.Errorf("%w: %v", ErrTaskPanicked, recovered))
+			// let the container handle the panic as usual
+			panic(recovered)
+		}
+	}()
+
+	value, err := fn(ctx)
+	f.complete(value, containerIndex, err)
+}
+
+// drop done the future with err, the task never run
+func (f *Future[T]) drop(err error) {
+	var zero T
+	f.complete(zero, 0, err)
+}
+
+// complete set the result once, later results are ignored
+func (f *Future[T]) complete(value T, containerIndex uint64, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.completeLocked(value, containerIndex, err)
+}
+
+func (f *Future[T]) completeLocked(value T, containerIndex uint64, err error) {
+	if f.state == futureDone {
+		return
+	}
+	f.state = futureDone
+
+	f.value = value
+	f.containerIndex = containerIndex
+	f.err = err
+	close(f.done)
+}
+
+// Get wait the task done and return its result, return ctx.Err() if ctx done first.
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Done closed when the task done.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Cancel cancel the task's ctx if it is running, or skip it if not started,
+// a skipped task's future is done with context.Canceled immediately.
+func (f *Future[T]) Cancel() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	switch f.state {
+	case futurePending:
+		var zero T
+		f.completeLocked(zero, 0, context.Canceled)
+	case futureRunning:
+		f.cancelRun()
+	}
+}
+
+// ContainerIndex is the container ran the task, 0 means the task never ran, only valid after done.
+func (f *Future[T]) ContainerIndex() uint64 {
+	<-f.done
+	return f.containerIndex
+}
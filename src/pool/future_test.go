@@ -0,0 +1,188 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	TestFutureResultNotMatch = errors.New("future result not match task result")
+	TestFutureNotDone        = errors.New("future should be done")
+)
+
+func TestSubmitFunc(t *testing.T) {
+
+	p, err := NewTaskPool(
+		2,
+		10,
+		WithPanicHandler(func(containerIndex uint64, recovered any, stack []byte) {}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	defer p.Shutdown(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	// value
+	{
+		f := SubmitFunc(p, func(ctx context.Context) (int, error) {
+			return 42, nil
+		})
+		value, err := f.Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != 42 || f.ContainerIndex() == 0 {
+			t.Fatal(TestFutureResultNotMatch)
+		}
+	}
+
+	// error
+	{
+		taskError := errors.New("task error")
+		f := SubmitFunc(p, func(ctx context.Context) (string, error) {
+			return "", taskError
+		})
+		_, err := f.Get(ctx)
+		if err != taskError {
+			t.Fatal(err)
+		}
+	}
+
+	// panic
+	{
+		f := SubmitFunc(p, func(ctx context.Context) (string, error) {
+			panic("boom")
+		})
+		_, err := f.Get(ctx)
+		if !errors.Is(err, ErrTaskPanicked) {
+			t.Fatal(err)
+		}
+	}
+
+	// cancel running task
+	{
+		started := make(chan struct{})
+		f := SubmitFunc(p, func(ctx context.Context) (struct{}, error) {
+			close(started)
+			<-ctx.Done()
+			return struct{}{}, ctx.Err()
+		})
+		<-started
+		f.Cancel()
+		select {
+		case <-f.Done():
+		case <-ctx.Done():
+			t.Fatal(TestFutureNotDone)
+		}
+		_, err := f.Get(ctx)
+		if err != context.Canceled {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+func TestSubmitFunc_submitError(t *testing.T) {
+
+	p, err := NewTaskPool(0, 1, WithOverflowPolicy(OverflowFailFast))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	err = p.Submit(func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := SubmitFunc(p, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	select {
+	case <-f.Done():
+	default:
+		t.Fatal(TestFutureNotDone)
+	}
+	_, err = f.Get(context.Background())
+	if err != ErrQueueFull {
+		t.Fatal(err)
+	}
+
+	f = SubmitFunc[int](p, nil)
+	_, err = f.Get(context.Background())
+	if err != submitTaskIsNil {
+		t.Fatal(err)
+	}
+
+}
+
+func TestFuture_CancelNotStarted(t *testing.T) {
+
+	p, err := NewTaskPool(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	defer p.Shutdown(context.Background())
+
+	// no container would start the task
+	p.Pause()
+
+	f := SubmitFunc(p, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	f.Cancel()
+	// cancel twice is fine
+	f.Cancel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	_, err = f.Get(ctx)
+	if err != context.Canceled {
+		t.Fatal(err)
+	}
+	if f.ContainerIndex() != 0 {
+		t.Fatal(TestFutureResultNotMatch)
+	}
+
+}
+
+func TestFuture_droppedByShutdown(t *testing.T) {
+
+	p, err := NewTaskPool(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	// the only container is busy, the future stay queued
+	block := make(chan struct{})
+	defer close(block)
+	err = p.Submit(func() { <-block })
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := SubmitFunc(p, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+	getCtx, getCancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer getCancel()
+	_, err = f.Get(getCtx)
+	if err != ErrPoolClosed {
+		t.Fatal(err)
+	}
+
+}
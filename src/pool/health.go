@@ -0,0 +1,114 @@
+package pool
+
+import (
+	"time"
+)
+
+// Health is the pool's health state judged by containers' failures in recent window, see CrashLoopDetection.
+type Health int
+
+const (
+	Healthy      Health = iota
+	Degraded            // failures in window >= DegradedThreshold
+	CrashLooping        // failures in window >= CrashLoopingThreshold
+)
+
+func (h Health) String() string {
+	switch h {
+	case Healthy:
+		return "healthy"
+	case Degraded:
+		return "degraded"
+	case CrashLooping:
+		return "crash_looping"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	defaultCrashLoopWindow                = time.Minute
+	defaultCrashLoopDegradedThreshold     = 3
+	defaultCrashLoopCrashLoopingThreshold = 10
+)
+
+// CrashLoopDetection decide the pool's health by how many containers failed in Window.
+// a container failed if it panicked, or exited normal(include containerEnd) after lived less than MinLifetime,
+// the same rule Backoff use with ResetAfter.
+type CrashLoopDetection struct {
+	Window                time.Duration // 0 means default 1 minute
+	DegradedThreshold     uint64        // 0 means default 3
+	CrashLoopingThreshold uint64        // 0 means default 10
+	MinLifetime           time.Duration // 0 means only panic is failure
+}
+
+// WithCrashLoopDetection set how the pool judge its health.
+func WithCrashLoopDetection(detection CrashLoopDetection) Option {
+	return func(o *options) {
+		o.crashLoopDetection = detection
+	}
+}
+
+// failed report whether a container exited by reason after lived lifetime is a failure
+func (d CrashLoopDetection) failed(reason ExitReason, lifetime time.Duration) bool {
+	return reason == ExitPanic || reason == ExitNormal && lifetime < d.MinLifetime
+}
+
+func (d CrashLoopDetection) withDefault() CrashLoopDetection {
+	if d.Window <= 0 {
+		d.Window = defaultCrashLoopWindow
+	}
+	if d.DegradedThreshold == 0 {
+		d.DegradedThreshold = defaultCrashLoopDegradedThreshold
+	}
+	if d.CrashLoopingThreshold == 0 {
+		d.CrashLoopingThreshold = defaultCrashLoopCrashLoopingThreshold
+	}
+	return d
+}
+
+func (s *Status) setCrashLoopDetection(detection CrashLoopDetection) {
+	s.healthMutex.Lock()
+	defer s.healthMutex.Unlock()
+
+	s.crashLoopDetection = detection.withDefault()
+}
+
+func (s *Status) recordFailure() {
+	s.healthMutex.Lock()
+	defer s.healthMutex.Unlock()
+
+	now := time.Now()
+	s.pruneFailures(now)
+	s.recentFailures = append(s.recentFailures, now)
+}
+
+// must hold s.healthMutex
+func (s *Status) pruneFailures(now time.Time) {
+	window := s.crashLoopDetection.withDefault().Window
+
+	i := 0
+	for i < len(s.recentFailures) && now.Sub(s.recentFailures[i]) > window {
+		i++
+	}
+	s.recentFailures = s.recentFailures[i:]
+}
+
+// Health report the pool is healthy, degraded or crash looping.
+func (s *Status) Health() Health {
+	s.healthMutex.Lock()
+	defer s.healthMutex.Unlock()
+
+	s.pruneFailures(time.Now())
+
+	detection := s.crashLoopDetection.withDefault()
+	failures := uint64(len(s.recentFailures))
+	switch {
+	case failures >= detection.CrashLoopingThreshold:
+		return CrashLooping
+	case failures >= detection.DegradedThreshold:
+		return Degraded
+	default:
+		return Healthy
+	}
+}
@@ -0,0 +1,135 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	TestStatusHealthNotMatch = errors.New("health not match failures in window")
+)
+
+func TestStatus_Health(t *testing.T) {
+
+	s := new(Status)
+	s.setCrashLoopDetection(CrashLoopDetection{
+		Window:                time.Millisecond * 100,
+		DegradedThreshold:     2,
+		CrashLoopingThreshold: 4,
+	})
+
+	if s.Health() != Healthy {
+		t.Fatal(TestStatusHealthNotMatch)
+	}
+
+	s.recordFailure()
+	s.recordFailure()
+	if s.Health() != Degraded {
+		t.Fatal(TestStatusHealthNotMatch)
+	}
+
+	s.recordFailure()
+	s.recordFailure()
+	if s.Health() != CrashLooping {
+		t.Fatal(TestStatusHealthNotMatch)
+	}
+
+	// failures out of window
+	time.Sleep(time.Millisecond * 150)
+	if s.Health() != Healthy {
+		t.Fatal(TestStatusHealthNotMatch)
+	}
+
+}
+
+func TestWithCrashLoopDetection(t *testing.T) {
+
+	p, err := NewBuildInLoopPool(
+		1,
+		func(containerEnd func(), containerIndex uint64) {
+			panic("boom")
+		},
+		WithPanicHandler(func(containerIndex uint64, recovered any, stack []byte) {}),
+		WithCrashLoopDetection(CrashLoopDetection{
+			Window:                time.Minute,
+			DegradedThreshold:     1,
+			CrashLoopingThreshold: 5,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	err = p.SetDetectExpectDuration(time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second * 10)
+	for p.Health() != CrashLooping {
+		if time.Now().After(deadline) {
+			t.Fatal(TestStatusHealthNotMatch)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// stop restarting, not steal cpu from other tests
+	_ = p.Shutdown(context.Background())
+
+}
+
+func TestWithCrashLoopDetection_minLifetime(t *testing.T) {
+
+	// containers end themselves immediately, restarted again and again
+	newPool := func(minLifetime time.Duration) *buildInLoopPool {
+		p, err := NewBuildInLoopPool(
+			1,
+			func(containerEnd func(), containerIndex uint64) {
+				containerEnd()
+			},
+			WithCrashLoopDetection(CrashLoopDetection{
+				Window:                time.Minute,
+				DegradedThreshold:     1,
+				CrashLoopingThreshold: 5,
+				MinLifetime:           minLifetime,
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+		err = p.SetDetectExpectDuration(time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	// exited faster than MinLifetime is failure
+	{
+		p := newPool(time.Second)
+		deadline := time.Now().Add(time.Second * 10)
+		for p.Health() != CrashLooping {
+			if time.Now().After(deadline) {
+				t.Fatal(TestStatusHealthNotMatch)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		_ = p.Shutdown(context.Background())
+	}
+
+	// without MinLifetime only panic is failure
+	{
+		p := newPool(0)
+		for p.GetExitedCount() < 10 {
+			time.Sleep(time.Millisecond)
+		}
+		if p.Health() != Healthy {
+			t.Fatal(TestStatusHealthNotMatch)
+		}
+		_ = p.Shutdown(context.Background())
+	}
+
+}
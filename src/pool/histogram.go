@@ -0,0 +1,145 @@
+package pool
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// histogramBucketCount buckets' upper bounds are 1µs, 2µs, 4µs ... 2^35µs(about 9.5 hours),
+// one more bucket for longer durations
+const histogramBucketCount = 36
+
+// histogram count durations into exponential buckets, lock free.
+type histogram struct {
+	buckets [histogramBucketCount + 1]atomic.Uint64
+	sum     atomic.Int64 // nanoseconds
+}
+
+func histogramBucketUpperBound(i int) time.Duration {
+	return time.Microsecond << i
+}
+
+// histogramBucketIndex is the smallest i that d <= histogramBucketUpperBound(i)
+func histogramBucketIndex(d time.Duration) int {
+	if d <= time.Microsecond {
+		return 0
+	}
+
+	micros := uint64((d + time.Microsecond - 1) / time.Microsecond)
+	i := bits.Len64(micros - 1)
+	if i > histogramBucketCount {
+		return histogramBucketCount
+	}
+	return i
+}
+
+func (h *histogram) observe(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.buckets[histogramBucketIndex(d)].Add(1)
+	h.sum.Add(int64(d))
+}
+
+func (h *histogram) snapshot() HistogramSnapshot {
+	snapshot := HistogramSnapshot{
+		Sum:     time.Duration(h.sum.Load()),
+		Buckets: make([]HistogramBucket, len(h.buckets)),
+	}
+
+	for i := range h.buckets {
+		count := h.buckets[i].Load()
+		snapshot.Count += count
+
+		snapshot.Buckets[i].Count = count
+		snapshot.Buckets[i].UpperBound = histogramBucketUpperBound(i)
+		if i == histogramBucketCount {
+			snapshot.Buckets[i].UpperBound = -1
+		}
+	}
+
+	snapshot.P50 = snapshot.Quantile(0.5)
+	snapshot.P90 = snapshot.Quantile(0.9)
+	snapshot.P99 = snapshot.Quantile(0.99)
+
+	return snapshot
+}
+
+// HistogramBucket is how many durations in (previous bucket's UpperBound, UpperBound],
+// UpperBound of the last bucket is -1 means no bound.
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// HistogramSnapshot is a histogram's counts at a moment.
+// buckets are exponential, so quantiles are estimated within a factor of 2.
+type HistogramSnapshot struct {
+	Count   uint64
+	Sum     time.Duration
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Buckets []HistogramBucket
+}
+
+// Quantile estimate the q(0 to 1) quantile by linear interpolation inside the bucket, 0 if no durations.
+func (h HistogramSnapshot) Quantile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	if q < 0 {
+		q = 0
+	}
+	if q > 1 {
+		q = 1
+	}
+
+	rank := q * float64(h.Count)
+
+	var (
+		cumulative float64
+		lower      time.Duration
+	)
+	for _, bucket := range h.Buckets {
+		count := float64(bucket.Count)
+		if count > 0 && cumulative+count >= rank {
+			if bucket.UpperBound < 0 {
+				// no bound, the best guess is the lower bound
+				return lower
+			}
+			return lower + time.Duration(float64(bucket.UpperBound-lower)*(rank-cumulative)/count)
+		}
+
+		cumulative += count
+		lower = bucket.UpperBound
+	}
+
+	return lower
+}
+
+// Stats is the histograms of a pool.
+type Stats struct {
+	// RunDuration is every execution of pool's function,
+	// a task for task pool, an iteration for build in loop pool. panicked executions are not observed.
+	RunDuration HistogramSnapshot
+	// ContainerLifetime is every exited container from start to exit.
+	ContainerLifetime HistogramSnapshot
+}
+
+func (s *Status) observeRunDuration(d time.Duration) {
+	s.runDurations.observe(d)
+}
+
+func (s *Status) observeContainerLifetime(d time.Duration) {
+	s.containerLifetimes.observe(d)
+}
+
+// Stats return histograms' snapshot, safe for concurrent use.
+func (s *Status) Stats() Stats {
+	return Stats{
+		RunDuration:       s.runDurations.snapshot(),
+		ContainerLifetime: s.containerLifetimes.snapshot(),
+	}
+}
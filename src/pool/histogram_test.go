@@ -0,0 +1,135 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	TestHistogramBucketIndexNotMatch = errors.New("histogram bucket index not match")
+	TestHistogramQuantileNotMatch    = errors.New("histogram quantile not match")
+	TestStatsNotMatch                = errors.New("stats not match")
+)
+
+func TestHistogramBucketIndex(t *testing.T) {
+
+	for d, expect := range map[time.Duration]int{
+		0:                                 0,
+		time.Microsecond:                  0,
+		time.Microsecond + 1:              1,
+		time.Microsecond * 2:              1,
+		time.Microsecond * 3:              2,
+		time.Microsecond * 4:              2,
+		time.Millisecond:                  10, // 1024µs
+		time.Microsecond << 35:            35,
+		time.Microsecond<<35 + 1:          36,
+		time.Duration(1<<63 - 1):          36,
+		histogramBucketUpperBound(20):     20,
+		histogramBucketUpperBound(20) + 1: 21,
+	} {
+		if i := histogramBucketIndex(d); i != expect {
+			t.Fatal(d, i, expect, TestHistogramBucketIndexNotMatch)
+		}
+	}
+
+}
+
+func TestHistogramSnapshot_Quantile(t *testing.T) {
+
+	h := new(histogram)
+	if h.snapshot().P99 != 0 {
+		t.Fatal(TestHistogramQuantileNotMatch)
+	}
+
+	// 90 fast, 10 slow
+	for i := 0; i < 90; i++ {
+		h.observe(time.Microsecond * 100)
+	}
+	for i := 0; i < 10; i++ {
+		h.observe(time.Millisecond * 100)
+	}
+
+	snapshot := h.snapshot()
+	if snapshot.Count != 100 || snapshot.Sum != time.Microsecond*100*90+time.Millisecond*100*10 {
+		t.Fatal(snapshot.Count, snapshot.Sum, TestHistogramQuantileNotMatch)
+	}
+
+	// estimated within a factor of 2
+	within := func(got time.Duration, expect time.Duration) bool {
+		return got >= expect/2 && got <= expect*2
+	}
+	if !within(snapshot.P50, time.Microsecond*100) || !within(snapshot.P90, time.Microsecond*100) {
+		t.Fatal(snapshot.P50, snapshot.P90, TestHistogramQuantileNotMatch)
+	}
+	if !within(snapshot.P99, time.Millisecond*100) {
+		t.Fatal(snapshot.P99, TestHistogramQuantileNotMatch)
+	}
+
+}
+
+func TestStatus_Stats(t *testing.T) {
+
+	iterations := atomic.Uint64{}
+	p, err := NewBuildInLoopPool(
+		1,
+		func(containerEnd func(), containerIndex uint64) {
+			time.Sleep(time.Millisecond)
+			if iterations.Add(1)%5 == 0 {
+				containerEnd()
+			}
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	for iterations.Load() < 10 {
+		time.Sleep(time.Millisecond)
+	}
+	err = p.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := p.Stats()
+	if stats.RunDuration.Count != iterations.Load() || stats.RunDuration.P50 < time.Millisecond/2 {
+		t.Fatal(stats.RunDuration, TestStatsNotMatch)
+	}
+	if stats.ContainerLifetime.Count < 2 || stats.ContainerLifetime.Count != p.GetExitedCount() {
+		t.Fatal(stats.ContainerLifetime, TestStatsNotMatch)
+	}
+
+}
+
+func TestStatus_Stats_taskPool(t *testing.T) {
+
+	p, err := NewTaskPool(2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	for i := 0; i < 5; i++ {
+		err = p.Submit(func() {
+			time.Sleep(time.Millisecond)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = p.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every task observed, not the containers taking tasks
+	stats := p.Stats()
+	if stats.RunDuration.Count != 5 || stats.RunDuration.P99 > time.Second {
+		t.Fatal(stats.RunDuration, TestStatsNotMatch)
+	}
+
+}
@@ -0,0 +1,80 @@
+package pool
+
+import (
+	"runtime/debug"
+)
+
+// WithOnContainerStart set hook called on the container's goroutine before its function first run,
+// e.g. open a connection for the container. hook panic is same as the function panic.
+func WithOnContainerStart(hook func(containerIndex uint64)) Option {
+	return func(o *options) {
+		o.onContainerStart = hook
+	}
+}
+
+// WithOnContainerExit set hook called on the container's goroutine after its function end by reason,
+// e.g. close the container's connection. the container is still counted as running until hook return,
+// hook panic is reported to PanicHandler.
+func WithOnContainerExit(hook func(containerIndex uint64, reason ExitReason)) Option {
+	return func(o *options) {
+		o.onContainerExit = hook
+	}
+}
+
+// WithBeforeIteration set hook called before every iteration of build in loop pool's function,
+// hook panic is same as the function panic. ignored by other pools.
+func WithBeforeIteration(hook func(containerIndex uint64)) Option {
+	return func(o *options) {
+		o.beforeIteration = hook
+	}
+}
+
+// WithAfterIteration set hook called after every iteration of build in loop pool's function returned,
+// hook panic is same as the function panic. ignored by other pools.
+func WithAfterIteration(hook func(containerIndex uint64)) Option {
+	return func(o *options) {
+		o.afterIteration = hook
+	}
+}
+
+// containerStarted call onContainerStart then create stateful pool's container state,
+// init error panic as *StateInitError so it is a failure same as the function panic
+func (s *supervisor) containerStarted(c *container) {
+	if s.onContainerStart != nil {
+		s.onContainerStart(c.index)
+	}
+
+	if s.initContainerState != nil {
+		state, err := s.initContainerState(c.index)
+		if err != nil {
+			panic(&StateInitError{ContainerIndex: c.index, Err: err})
+		}
+		c.state = state
+	}
+}
+
+// containerExiting clean up container state then call onContainerExit,
+// the container is exiting so panic is only reported
+func (s *supervisor) containerExiting(c *container, reason ExitReason) {
+	if s.cleanupContainerState != nil && c.state != nil {
+		s.runExitingHook(c, func() {
+			s.cleanupContainerState(c.state)
+		})
+	}
+
+	if s.onContainerExit != nil {
+		s.runExitingHook(c, func() {
+			s.onContainerExit(c.index, reason)
+		})
+	}
+}
+
+func (s *supervisor) runExitingHook(c *container, hook func()) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.panicHandler(c.index, recovered, debug.Stack())
+		}
+	}()
+
+	hook()
+}
@@ -0,0 +1,157 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	TestHookNotCalled     = errors.New("hook not called as expected")
+	TestHookPanicNotMatch = errors.New("hook panic not handled as expected")
+)
+
+func TestHooks(t *testing.T) {
+
+	var (
+		mutex   = sync.Mutex{}
+		calls   = make(map[uint64][]string)
+		reasons = make(map[uint64]ExitReason)
+		record  = func(containerIndex uint64, call string) {
+			mutex.Lock()
+			calls[containerIndex] = append(calls[containerIndex], call)
+			mutex.Unlock()
+		}
+	)
+
+	p, err := NewBuildInLoopPoolWithContext(
+		2,
+		func(ctx context.Context, containerEnd func(), containerIndex uint64) {
+			record(containerIndex, "run")
+			<-ctx.Done()
+		},
+		WithOnContainerStart(func(containerIndex uint64) {
+			record(containerIndex, "start")
+		}),
+		WithOnContainerExit(func(containerIndex uint64, reason ExitReason) {
+			record(containerIndex, "exit")
+			mutex.Lock()
+			reasons[containerIndex] = reason
+			mutex.Unlock()
+		}),
+		WithBeforeIteration(func(containerIndex uint64) {
+			record(containerIndex, "before")
+		}),
+		WithAfterIteration(func(containerIndex uint64) {
+			record(containerIndex, "after")
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	for {
+		mutex.Lock()
+		started := len(calls[1]) == 3 && len(calls[2]) == 3
+		mutex.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.SetExpectRunningCountAndWait(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	expect := "start before run after exit"
+	for _, index := range []uint64{1, 2} {
+		got := ""
+		for i, call := range calls[index] {
+			if i > 0 {
+				got += " "
+			}
+			got += call
+		}
+		if got != expect {
+			t.Fatal(index, got, TestHookNotCalled)
+		}
+	}
+	// newest retired first
+	if reasons[2] != ExitRetired || reasons[1] != ExitShutdown {
+		t.Fatal(reasons, TestHookNotCalled)
+	}
+
+}
+
+func TestHooks_panic(t *testing.T) {
+
+	var (
+		mutex     = sync.Mutex{}
+		recovered []any
+		exits     = make(chan ExitReason, 10)
+	)
+
+	p, err := NewPool(
+		1,
+		func(containerIndex uint64) {
+			time.Sleep(time.Hour)
+		},
+		WithPanicHandler(func(containerIndex uint64, r any, stack []byte) {
+			mutex.Lock()
+			recovered = append(recovered, r)
+			mutex.Unlock()
+		}),
+		WithRestartPolicy(RestartNever),
+		// start hook panic is same as the function panic
+		WithOnContainerStart(func(containerIndex uint64) {
+			panic("start")
+		}),
+		// exit hook panic is only reported
+		WithOnContainerExit(func(containerIndex uint64, reason ExitReason) {
+			exits <- reason
+			panic("exit")
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	select {
+	case reason := <-exits:
+		if reason != ExitPanic {
+			t.Fatal(reason, TestHookPanicNotMatch)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal(TestHookNotCalled)
+	}
+
+	err = p.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(recovered) != 2 || recovered[0] != "start" || recovered[1] != "exit" {
+		t.Fatal(recovered, TestHookPanicNotMatch)
+	}
+	if p.GetPanickedCount() != 1 {
+		t.Fatal(TestHookPanicNotMatch)
+	}
+
+}
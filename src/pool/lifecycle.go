@@ -0,0 +1,57 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+type lifecycle struct {
+	// ctx is the parent of every container's context, cancel it when shutdown
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	shutdownSignal <-chan struct{}
+
+	// supervisors are the goroutines keep the pool running as expected
+	supervisorWaitGroup sync.WaitGroup
+	containerWaitGroup  sync.WaitGroup
+}
+
+func newLifecycle() *lifecycle {
+	l := new(lifecycle)
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	l.shutdownSignal = l.ctx.Done()
+	return l
+}
+
+func (l *lifecycle) isShutdown() bool {
+	select {
+	case <-l.shutdownSignal:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stop the pool create new containers, cancel every container's context
+// and wait for running containers end of execution.
+// if ctx done before all containers end of execution, return ctx.Err().
+// call Shutdown again is safe, it will wait for running containers again.
+func (l *lifecycle) Shutdown(ctx context.Context) error {
+	l.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		// supervisors must be stopped first, they are the only one would add containers
+		l.supervisorWaitGroup.Wait()
+		l.containerWaitGroup.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
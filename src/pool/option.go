@@ -0,0 +1,62 @@
+package pool
+
+import "time"
+
+// Option configure a pool when it is created.
+type Option func(o *options)
+
+type options struct {
+	panicHandler  PanicHandler
+	restartPolicy RestartPolicy
+	backoff       Backoff
+
+	crashLoopDetection CrashLoopDetection
+
+	scaleDownPolicy ScaleDownPolicy
+
+	onContainerStart func(containerIndex uint64)
+	onContainerExit  func(containerIndex uint64, reason ExitReason)
+
+	// build in loop pool only
+	beforeIteration func(containerIndex uint64)
+	afterIteration  func(containerIndex uint64)
+
+	// stateful pool only, container's state is created when it start and cleaned up when it exit
+	initContainerState    func(containerIndex uint64) (state any, err error)
+	cleanupContainerState func(state any)
+
+	// task pool only
+	overflowPolicy        OverflowPolicy
+	priorityLevels        int
+	priorityAgingInterval time.Duration
+}
+
+func newOptions(opts []Option) *options {
+	o := new(options)
+	o.panicHandler = defaultPanicHandler
+	o.restartPolicy = RestartAlways
+	o.priorityLevels = 1
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithPanicHandler set the handler called when a container's function panic, nil means use default handler(log it).
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(o *options) {
+		if handler == nil {
+			handler = defaultPanicHandler
+		}
+		o.panicHandler = handler
+	}
+}
+
+// WithRestartPolicy set whether the pool replace a container after it exited, default is RestartAlways.
+func WithRestartPolicy(policy RestartPolicy) Option {
+	return func(o *options) {
+		o.restartPolicy = policy
+	}
+}
@@ -1,17 +1,19 @@
 package pool
 
 import (
+	"context"
 	"errors"
-	"sync"
+	"runtime/debug"
 	"time"
 )
 
 type pool struct {
-	*Status
+	*supervisor
 
-	reviseContainerRunningCountAsExpectCountMutex sync.Mutex
+	runFunc func(ctx context.Context, containerIndex uint64)
 
-	runFunc func(containerIndex uint64)
+	// observeRunFunc observe runFunc's duration, task pool's runFunc take tasks and observe every task instead
+	observeRunFunc bool
 }
 
 var (
@@ -20,20 +22,31 @@ var (
 
 func newPool(
 	expectRunningCount uint64,
-	runFunc func(containerIndex uint64),
+	runFunc func(ctx context.Context, containerIndex uint64),
+	opts ...Option,
 ) (p *pool, err error) {
 
-	p = new(pool)
-	p.Status = new(Status)
-
-	// set default revise  running count
-	err = p.SetDetectExpectDuration(defaultDetectExpectDuration)
+	p, err = newPoolWithoutStart(expectRunningCount, runFunc, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	// set expect running  count
-	err = p.SetExpectRunningCount(expectRunningCount)
+	p.observeRunFunc = true
+	p.start()
+
+	return p, err
+}
+
+// newPoolWithoutStart create the pool but not start supervisor, for wrapper need prepare things before containers running
+func newPoolWithoutStart(
+	expectRunningCount uint64,
+	runFunc func(ctx context.Context, containerIndex uint64),
+	opts ...Option,
+) (p *pool, err error) {
+
+	p = new(pool)
+
+	p.supervisor, err = newSupervisor(expectRunningCount, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -44,34 +57,30 @@ func newPool(
 	}
 
 	p.runFunc = runFunc
-
-	// if GetNowRunningCount() < GetExpectRunningCount() then create containers
-	go p.reviseContainerRunningCountAsExpectCount()
+	p.supervisor.containerStart = p.containerStart
 
 	return p, err
 }
 
-func (p *pool) containerStart(containerIndex uint64) {
-	p.incrNowRunningCount()
-	defer p.decrNowRunningCount()
-
-	p.reviseContainerRunningCountAsExpectCountMutex.Unlock()
-
-	p.runFunc(containerIndex)
-
-	return
-}
+func (p *pool) containerStart(c *container) {
+	defer func() {
+		reason := ExitNormal
+		if recovered := recover(); recovered != nil {
+			reason = ExitPanic
+			p.panicHandler(c.index, recovered, debug.Stack())
+		}
 
-func (p *pool) reviseContainerRunningCountAsExpectCount() {
-	for {
-		p.reviseContainerRunningCountAsExpectCountMutex.Lock()
+		p.containerExit(c, reason)
+	}()
 
-		if p.GetNowRunningCount() == p.GetExpectRunningCount() || p.GetNowRunningCount() > p.GetExpectRunningCount() {
-			p.reviseContainerRunningCountAsExpectCountMutex.Unlock()
-			time.Sleep(p.GetDetectExpectDuration())
-			continue
-		}
+	p.containerStarted(c)
 
-		go p.containerStart(p.newContainerIndex())
+	c.busy.Store(true)
+	start := time.Now()
+	p.runFunc(c.ctx, c.index)
+	if p.observeRunFunc {
+		p.observeRunDuration(time.Since(start))
 	}
+
+	return
 }
@@ -1,8 +1,27 @@
 package pool
 
+import "context"
+
 func NewPool(
 	expectRunningCount uint64,
 	runFunc func(containerIndex uint64),
+	opts ...Option,
+) (p *pool, err error) {
+	if runFunc == nil {
+		return newPool(expectRunningCount, nil, opts...)
+	}
+
+	return newPool(expectRunningCount, func(ctx context.Context, containerIndex uint64) {
+		runFunc(containerIndex)
+	}, opts...)
+}
+
+// NewPoolWithContext same as NewPool, but runFunc's ctx will be canceled
+// when the container become surplus(SetExpectRunningCount lower than running count) or the pool shutdown.
+func NewPoolWithContext(
+	expectRunningCount uint64,
+	runFunc func(ctx context.Context, containerIndex uint64),
+	opts ...Option,
 ) (p *pool, err error) {
-	return newPool(expectRunningCount, runFunc)
+	return newPool(expectRunningCount, runFunc, opts...)
 }
@@ -1,6 +1,7 @@
 package pool
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"testing"
@@ -11,7 +12,7 @@ func TestNewPool(t *testing.T) {
 
 	var err error
 
-	_, err = NewPool(
+	p, err := NewPool(
 		1,
 		func(containerIndex uint64) {
 			time.Sleep(time.Second)
@@ -20,6 +21,7 @@ func TestNewPool(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	_, err = NewPool(
 		1,
@@ -42,6 +44,7 @@ func TestPool_SetDetectExpectDuration(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	var detectExpectDuration time.Duration
 
@@ -85,6 +88,7 @@ func TestPool_GetDetectExpectDuration(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	if p.GetDetectExpectDuration() != defaultDetectExpectDuration {
 		t.Fatal(TestPoolGetDetectExpectDurationDefaultValueError)
@@ -128,6 +132,7 @@ func TestPool_SetExpectRunningCount(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	expectRunningCount = 0
 	err = p.SetExpectRunningCount(expectRunningCount)
@@ -156,6 +161,7 @@ func TestPool_GetExpectRunningCount(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	shutdownAtCleanup(t, p)
 
 	expectRunningCount = 0
 	err = p.SetExpectRunningCount(expectRunningCount)
@@ -199,6 +205,7 @@ func TestPool_GetNowRunningCount(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		shutdownAtCleanup(t, p)
 
 		wg.Wait()
 
@@ -233,6 +240,7 @@ func TestPool_GetNowRunningCount(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		shutdownAtCleanup(t, p)
 
 		wg.Wait()
 
@@ -280,6 +288,7 @@ func TestPool_GetNowRunningCount(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
+		shutdownAtCleanup(t, p)
 
 		//
 		if expectRunningCount != p.GetExpectRunningCount() {
@@ -318,3 +327,314 @@ func TestPool_GetNowRunningCount(t *testing.T) {
 	}
 
 }
+
+var (
+	TestPoolShutdownNowRunningCountNotZero = errors.New("now running count should be zero after shutdown")
+)
+
+func TestPool_Shutdown(t *testing.T) {
+
+	{
+		p, err := NewPool(
+			10,
+			func(containerIndex uint64) {
+				time.Sleep(time.Millisecond)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+
+		err = p.Shutdown(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.GetNowRunningCount() != 0 {
+			t.Fatal(TestPoolShutdownNowRunningCountNotZero)
+		}
+
+		// shutdown again is safe
+		err = p.Shutdown(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	{
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+
+		p, err := NewPool(
+			1,
+			func(containerIndex uint64) {
+				wg.Done()
+				time.Sleep(time.Hour)
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+
+		wg.Wait()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+
+		err = p.Shutdown(ctx)
+		if err != context.DeadlineExceeded {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+var (
+	TestNewPoolWithContextCanceledCountNotMatch = errors.New("canceled containers count not match surplus count")
+)
+
+func TestNewPoolWithContext(t *testing.T) {
+
+	_, err := NewPoolWithContext(
+		1,
+		nil,
+	)
+	if err != newPoolRunFuncIsNil {
+		t.Fatal(err)
+	}
+
+	var (
+		expectRunningCount uint64 = 10
+		canceledCount      uint64 = 0
+		canceledCountMutex        = sync.Mutex{}
+	)
+
+	p, err := NewPoolWithContext(
+		expectRunningCount,
+		func(ctx context.Context, containerIndex uint64) {
+			<-ctx.Done()
+
+			canceledCountMutex.Lock()
+			canceledCount++
+			canceledCountMutex.Unlock()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	err = p.SetDetectExpectDuration(time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p.GetNowRunningCount() != expectRunningCount {
+		time.Sleep(time.Millisecond)
+	}
+
+	// scale down, surplus containers' ctx should be canceled
+	expectRunningCount = 3
+	err = p.SetExpectRunningCount(expectRunningCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p.GetNowRunningCount() != expectRunningCount {
+		time.Sleep(time.Millisecond)
+	}
+
+	canceledCountMutex.Lock()
+	if canceledCount != 7 {
+		t.Fatal(TestNewPoolWithContextCanceledCountNotMatch)
+	}
+	canceledCountMutex.Unlock()
+
+	// shutdown cancel the rest
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+var (
+	TestPoolPanicHandlerNotCalled              = errors.New("panic handler not called")
+	TestPoolRestartPolicyExpectCountNotMatch   = errors.New("expect running count not match restart policy")
+	TestPoolRestartPolicyPanickedCountNotMatch = errors.New("panicked count not match restart policy")
+)
+
+func TestPool_PanicHandler(t *testing.T) {
+
+	recoveredChan := make(chan any, 1)
+
+	p, err := NewPool(
+		1,
+		func(containerIndex uint64) {
+			panic("boom")
+		},
+		WithPanicHandler(func(containerIndex uint64, recovered any, stack []byte) {
+			select {
+			case recoveredChan <- recovered:
+			default:
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	select {
+	case recovered := <-recoveredChan:
+		if recovered != "boom" {
+			t.Fatal(TestPoolPanicHandlerNotCalled)
+		}
+	case <-time.After(time.Second * 10):
+		t.Fatal(TestPoolPanicHandlerNotCalled)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func TestPool_RestartPolicy(t *testing.T) {
+
+	var (
+		panicHandler = WithPanicHandler(func(containerIndex uint64, recovered any, stack []byte) {})
+	)
+
+	// never: every exited container give up its slot
+	{
+		p, err := NewPool(
+			3,
+			func(containerIndex uint64) {},
+			WithRestartPolicy(RestartNever),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+		err = p.SetDetectExpectDuration(time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for p.GetExpectRunningCount() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if p.GetExitedCount() != 3 {
+			t.Fatal(TestPoolRestartPolicyExpectCountNotMatch)
+		}
+	}
+
+	// on failure: panicked container replaced at most 2 times
+	{
+		p, err := NewPool(
+			1,
+			func(containerIndex uint64) {
+				panic("boom")
+			},
+			panicHandler,
+			WithRestartPolicy(RestartOnFailure(2)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+		err = p.SetDetectExpectDuration(time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for p.GetExpectRunningCount() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if p.GetPanickedCount() != 3 || p.GetRestartedCount() != 2 {
+			t.Fatal(TestPoolRestartPolicyPanickedCountNotMatch)
+		}
+	}
+
+	// on failure: containers panicked concurrently never restart more than max
+	{
+		p, err := NewPool(
+			20,
+			func(containerIndex uint64) {
+				panic("boom")
+			},
+			panicHandler,
+			WithRestartPolicy(RestartOnFailure(3)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+		err = p.SetDetectExpectDuration(time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for p.GetExpectRunningCount() != 0 || p.GetNowRunningCount() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if p.GetPanickedCount() != 23 || p.GetRestartedCount() != 3 {
+			t.Fatal(TestPoolRestartPolicyPanickedCountNotMatch)
+		}
+	}
+
+	// always: panicked container always replaced
+	{
+		p, err := NewPool(
+			1,
+			func(containerIndex uint64) {
+				panic("boom")
+			},
+			panicHandler,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+		err = p.SetDetectExpectDuration(time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for p.GetPanickedCount() < 5 {
+			time.Sleep(time.Millisecond)
+		}
+		if p.GetExpectRunningCount() != 1 {
+			t.Fatal(TestPoolRestartPolicyExpectCountNotMatch)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		err = p.Shutdown(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+type shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// shutdownAtCleanup shutdown p when the test end, so its containers not starve later tests.
+// containers ignore ctx are not waited long.
+func shutdownAtCleanup(t *testing.T, p shutdowner) {
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		_ = p.Shutdown(ctx)
+	})
+}
@@ -0,0 +1,102 @@
+package pool
+
+import (
+	"log"
+	"time"
+)
+
+// PanicHandler called on the panicked container's goroutine after recovered.
+type PanicHandler func(containerIndex uint64, recovered any, stack []byte)
+
+func defaultPanicHandler(containerIndex uint64, recovered any, stack []byte) {
+	log.Printf("goroutine_pool: container #%d panic: %v\n%s", containerIndex, recovered, stack)
+}
+
+// ExitReason is why a container end.
+type ExitReason int
+
+const (
+	ExitNormal   ExitReason = iota // function end of execution(or containerEnd called for build in loop pool)
+	ExitPanic                      // function panicked
+	ExitRetired                    // container is surplus and canceled by pool
+	ExitShutdown                   // pool is shutting down
+)
+
+func (r ExitReason) String() string {
+	switch r {
+	case ExitNormal:
+		return "normal"
+	case ExitPanic:
+		return "panic"
+	case ExitRetired:
+		return "retired"
+	case ExitShutdown:
+		return "shutdown"
+	default:
+		return "unknown"
+	}
+}
+
+type restartPolicyKind int
+
+const (
+	restartAlways restartPolicyKind = iota
+	restartNever
+	restartOnFailure
+)
+
+// RestartPolicy decide whether the pool replace a container after it exited normal or panicked.
+// when a container would not be replaced, the pool give up its slot: expect running count decrement 1.
+// retired and shutdown containers are never replaced.
+type RestartPolicy struct {
+	kind        restartPolicyKind
+	maxRestarts uint64
+}
+
+var (
+	// RestartAlways replace every exited container, it is the default.
+	RestartAlways = RestartPolicy{kind: restartAlways}
+	// RestartNever never replace exited container.
+	RestartNever = RestartPolicy{kind: restartNever}
+)
+
+// RestartOnFailure replace only panicked containers, at most maxRestarts times in pool's life(0 means no limit).
+func RestartOnFailure(maxRestarts uint64) RestartPolicy {
+	return RestartPolicy{kind: restartOnFailure, maxRestarts: maxRestarts}
+}
+
+// shouldRestart not check maxRestarts, panicked containers reserve a restart by Status.reserveRestart
+func (rp RestartPolicy) shouldRestart(reason ExitReason) bool {
+	switch rp.kind {
+	case restartNever:
+		return false
+	case restartOnFailure:
+		return reason == ExitPanic
+	default:
+		return true
+	}
+}
+
+// containerExited record exit, and give up the container's slot if the restart policy not allow replace it.
+// report whether the container would be replaced.
+func containerExited(s *Status, o *options, reason ExitReason, lifetime time.Duration) (replace bool) {
+	s.incrExitedCount()
+
+	switch reason {
+	case ExitRetired, ExitShutdown:
+		return false
+	case ExitPanic:
+		s.incrPanickedCount()
+	}
+	if o.crashLoopDetection.failed(reason, lifetime) {
+		s.recordFailure()
+	}
+
+	if !o.restartPolicy.shouldRestart(reason) ||
+		reason == ExitPanic && !s.reserveRestart(o.restartPolicy.maxRestarts) {
+		s.decrExpectRunningCount()
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,54 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	newStatefulPoolRunFuncIsNil = errors.New("func is nil,the pool would not start")
+)
+
+// StateInitError is reported to PanicHandler as recovered when a stateful pool's init return error,
+// the container exited with ExitPanic, so restart policy, backoff and crash loop detection apply.
+type StateInitError struct {
+	ContainerIndex uint64
+	Err            error
+}
+
+func (e *StateInitError) Error() string {
+	return fmt.Sprintf("init state of container #%d: %v", e.ContainerIndex, e.Err)
+}
+
+func (e *StateInitError) Unwrap() error {
+	return e.Err
+}
+
+// withContainerState make the pool create state by init when a container start and clean up it when the container exit,
+// nil init means zero value, nil cleanup means nothing to do.
+func withContainerState[S any](init func(containerIndex uint64) (S, error), cleanup func(state *S)) Option {
+	return func(o *options) {
+		o.initContainerState = func(containerIndex uint64) (any, error) {
+			state := new(S)
+			if init == nil {
+				return state, nil
+			}
+
+			var err error
+			*state, err = init(containerIndex)
+			return state, err
+		}
+
+		if cleanup != nil {
+			o.cleanupContainerState = func(state any) {
+				cleanup(state.(*S))
+			}
+		}
+	}
+}
+
+// containerState is the state created for the container ctx belong to
+func containerState[S any](ctx context.Context) *S {
+	return containerFromContext(ctx).state.(*S)
+}
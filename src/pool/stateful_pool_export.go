@@ -0,0 +1,41 @@
+package pool
+
+import "context"
+
+// NewStatefulPool same as NewPoolWithContext, but every container has its own state.
+// init create the state on the container's goroutine when it start(after OnContainerStart hook),
+// run get the state, cleanup is called with the state when the container exit(before OnContainerExit hook).
+// init error is a failure same as run panic, see StateInitError. nil init means zero value state.
+func NewStatefulPool[S any](
+	expectRunningCount uint64,
+	init func(containerIndex uint64) (S, error),
+	run func(ctx context.Context, state *S),
+	cleanup func(state *S),
+	opts ...Option,
+) (p *pool, err error) {
+	if run == nil {
+		return nil, newStatefulPoolRunFuncIsNil
+	}
+
+	return newPool(expectRunningCount, func(ctx context.Context, containerIndex uint64) {
+		run(ctx, containerState[S](ctx))
+	}, append([]Option{withContainerState(init, cleanup)}, opts...)...)
+}
+
+// NewStatefulBuildInLoopPool same as NewBuildInLoopPoolWithContext, but every container has its own state,
+// it is passed to every iteration of the container. see NewStatefulPool for init and cleanup.
+func NewStatefulBuildInLoopPool[S any](
+	expectRunningCount uint64,
+	init func(containerIndex uint64) (S, error),
+	run func(ctx context.Context, containerEnd func(), state *S),
+	cleanup func(state *S),
+	opts ...Option,
+) (p *buildInLoopPool, err error) {
+	if run == nil {
+		return nil, newStatefulPoolRunFuncIsNil
+	}
+
+	return newBuildInLoopPool(expectRunningCount, func(ctx context.Context, containerEnd func(), containerIndex uint64) {
+		run(ctx, containerEnd, containerState[S](ctx))
+	}, append([]Option{withContainerState(init, cleanup)}, opts...)...)
+}
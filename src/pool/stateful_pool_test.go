@@ -0,0 +1,170 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	TestStateNotMatch     = errors.New("container state not match")
+	TestStateNotCleanedUp = errors.New("container state not cleaned up")
+	TestStateInitFailed   = errors.New("init state failed")
+)
+
+type testConn struct {
+	containerIndex uint64
+	iterations     int
+	closed         bool
+}
+
+func TestNewStatefulBuildInLoopPool(t *testing.T) {
+
+	var (
+		mutex   = sync.Mutex{}
+		cleaned []*testConn
+	)
+
+	p, err := NewStatefulBuildInLoopPool(
+		2,
+		func(containerIndex uint64) (*testConn, error) {
+			return &testConn{containerIndex: containerIndex}, nil
+		},
+		func(ctx context.Context, containerEnd func(), conn **testConn) {
+			(*conn).iterations++
+			if (*conn).iterations == 3 {
+				containerEnd()
+			}
+		},
+		func(conn **testConn) {
+			(*conn).closed = true
+
+			mutex.Lock()
+			cleaned = append(cleaned, *conn)
+			mutex.Unlock()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	var endedByContainerEnd []*testConn
+	for {
+		mutex.Lock()
+		if len(cleaned) >= 4 {
+			endedByContainerEnd = append(endedByContainerEnd, cleaned...)
+		}
+		mutex.Unlock()
+		if len(endedByContainerEnd) != 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	err = p.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	// every container had its own state for all iterations, cleaned up once
+	seen := make(map[uint64]bool)
+	for _, conn := range cleaned {
+		if seen[conn.containerIndex] || !conn.closed {
+			t.Fatal(conn, TestStateNotCleanedUp)
+		}
+		seen[conn.containerIndex] = true
+	}
+	for _, conn := range endedByContainerEnd {
+		if conn.iterations != 3 {
+			t.Fatal(conn, TestStateNotMatch)
+		}
+	}
+
+}
+
+func TestNewStatefulPool(t *testing.T) {
+
+	type state struct {
+		containerIndex uint64
+		cleaned        bool
+	}
+
+	var (
+		mutex     = sync.Mutex{}
+		recovered []any
+		cleaned   = make(chan *state, 10)
+		running   = make(chan *state, 10)
+	)
+
+	p, err := NewStatefulPool(
+		1,
+		func(containerIndex uint64) (state, error) {
+			// first container failed, it is replaced
+			if containerIndex == 1 {
+				return state{}, TestStateInitFailed
+			}
+			return state{containerIndex: containerIndex}, nil
+		},
+		func(ctx context.Context, s *state) {
+			running <- s
+			<-ctx.Done()
+		},
+		func(s *state) {
+			s.cleaned = true
+			cleaned <- s
+		},
+		WithPanicHandler(func(containerIndex uint64, r any, stack []byte) {
+			mutex.Lock()
+			recovered = append(recovered, r)
+			mutex.Unlock()
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	var s *state
+	select {
+	case s = <-running:
+	case <-time.After(time.Second * 5):
+		t.Fatal(TestStateNotMatch)
+	}
+	if s.containerIndex != 2 {
+		t.Fatal(s, TestStateNotMatch)
+	}
+
+	err = p.Shutdown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only created state is cleaned up
+	if len(cleaned) != 1 || <-cleaned != s || !s.cleaned {
+		t.Fatal(TestStateNotCleanedUp)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(recovered) != 1 {
+		t.Fatal(recovered, TestStateNotMatch)
+	}
+	var initErr *StateInitError
+	if err, ok := recovered[0].(error); !ok || !errors.As(err, &initErr) || !errors.Is(err, TestStateInitFailed) || initErr.ContainerIndex != 1 {
+		t.Fatal(recovered[0], TestStateNotMatch)
+	}
+	if p.GetPanickedCount() != 1 {
+		t.Fatal(TestStateNotMatch)
+	}
+
+	_, err = NewStatefulPool[state](1, nil, nil, nil)
+	if err != newStatefulPoolRunFuncIsNil {
+		t.Fatal(err)
+	}
+
+}
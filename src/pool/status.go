@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,13 +12,37 @@ const (
 	defaultDetectExpectDuration = time.Second
 )
 
+// Status counters are lock free, safe for concurrent use.
 type Status struct {
-	expectRunningCount   uint64
-	nowRunningCount      uint64
-	nowRunningCountMutex sync.Mutex
-	containerIndex       uint64
-	containerIndexMutex  sync.Mutex
-	detectExpectDuration time.Duration
+	expectRunningCount   atomic.Uint64
+	nowRunningCount      atomic.Uint64
+	containerIndex       atomic.Uint64
+	detectExpectDuration atomic.Int64
+	paused               atomic.Bool
+
+	exitedCount    atomic.Uint64
+	panickedCount  atomic.Uint64
+	restartedCount atomic.Uint64
+
+	runDurations       histogram
+	containerLifetimes histogram
+
+	queueDepths []atomic.Uint64 // task pool only, index is priority, init before the pool start
+
+	crashLoopDetection CrashLoopDetection
+	recentFailures     []time.Time
+	healthMutex        sync.Mutex
+
+	// expectChangedSignal wake up the supervisor
+	expectChangedSignal chan struct{}
+
+	events EventBroker
+}
+
+func newStatus() *Status {
+	s := new(Status)
+	s.expectChangedSignal = make(chan struct{}, 1)
+	return s
 }
 
 var (
@@ -30,65 +55,175 @@ func (s *Status) SetExpectRunningCount(count uint64) (err error) {
 		return err
 	}
 
-	s.expectRunningCount = count
+	previous := s.expectRunningCount.Swap(count)
+
+	notify(s.expectChangedSignal)
+
+	if previous != count {
+		s.events.Publish(Event{Type: EventExpectChanged, From: previous, To: count})
+	}
 
 	return nil
 }
 func (s *Status) GetExpectRunningCount() uint64 {
-	return s.expectRunningCount
+	return s.expectRunningCount.Load()
+}
+
+// decrExpectRunningCount used when the pool give up a container's slot
+func (s *Status) decrExpectRunningCount() {
+	for {
+		count := s.expectRunningCount.Load()
+		if count == 0 {
+			break
+		}
+		if s.expectRunningCount.CompareAndSwap(count, count-1) {
+			s.events.Publish(Event{Type: EventExpectChanged, From: count, To: count - 1})
+			break
+		}
+	}
+
+	notify(s.expectChangedSignal)
 }
 
 var (
 	setDetectExpectDurationMinDurationError = errors.New("min duration is millisecond") // min duration is millisecond because of cpu resource
 )
 
+// SetDetectExpectDuration set how often the pool detect running count as a safety net,
+// the pool revise running count immediately when expect changed or container exited, 0 means never detect.
 func (s *Status) SetDetectExpectDuration(duration time.Duration) (err error) {
-	if duration < time.Millisecond {
+	if duration != 0 && duration < time.Millisecond {
 		err = setDetectExpectDurationMinDurationError
 		return
 	}
 
-	s.detectExpectDuration = duration
+	s.detectExpectDuration.Store(int64(duration))
+
+	notify(s.expectChangedSignal)
 
 	return
 }
 func (s *Status) GetDetectExpectDuration() time.Duration {
-	return s.detectExpectDuration
+	return time.Duration(s.detectExpectDuration.Load())
 }
 
-func (s *Status) incrNowRunningCount() {
-	s.nowRunningCountMutex.Lock()
-	defer s.nowRunningCountMutex.Unlock()
+// Pause retire every container but keep expect running count, Resume bring them back.
+// task pool still accept tasks while paused, they wait in queue.
+func (s *Status) Pause() {
+	s.paused.Store(true)
 
-	s.nowRunningCount++
+	notify(s.expectChangedSignal)
 }
-func (s *Status) decrNowRunningCount() {
-	s.nowRunningCountMutex.Lock()
-	defer s.nowRunningCountMutex.Unlock()
+func (s *Status) Resume() {
+	s.paused.Store(false)
 
-	s.nowRunningCount--
+	notify(s.expectChangedSignal)
+}
+func (s *Status) IsPaused() bool {
+	return s.paused.Load()
+}
+
+// getTargetRunningCount is how many containers the supervisor keep running, 0 while paused
+func (s *Status) getTargetRunningCount() uint64 {
+	if s.IsPaused() {
+		return 0
+	}
+	return s.GetExpectRunningCount()
+}
+
+func (s *Status) incrNowRunningCount() {
+	s.nowRunningCount.Add(1)
+}
+func (s *Status) decrNowRunningCount() {
+	s.nowRunningCount.Add(^uint64(0))
 }
 func (s *Status) GetNowRunningCount() uint64 {
-	s.nowRunningCountMutex.Lock()
-	defer s.nowRunningCountMutex.Unlock()
-	return s.nowRunningCount
+	return s.nowRunningCount.Load()
 }
 
-func (s *Status) newContainerIndex() uint64 {
-	s.containerIndexMutex.Lock()
-	defer s.containerIndexMutex.Unlock()
+func (s *Status) incrExitedCount() {
+	s.exitedCount.Add(1)
+}
 
-	if s.containerIndex == math.MaxUint64 {
-		s.containerIndex = 0
+// GetExitedCount is how many containers exited(include panicked)
+func (s *Status) GetExitedCount() uint64 {
+	return s.exitedCount.Load()
+}
+
+func (s *Status) incrPanickedCount() {
+	s.panickedCount.Add(1)
+}
+
+// GetPanickedCount is how many containers exited by panic
+func (s *Status) GetPanickedCount() uint64 {
+	return s.panickedCount.Load()
+}
+
+// reserveRestart count a restart if restarted less than maxRestarts(0 means no limit), report whether counted.
+// concurrent exits reserve by CAS, so restarts never exceed maxRestarts
+func (s *Status) reserveRestart(maxRestarts uint64) bool {
+	for {
+		count := s.restartedCount.Load()
+		if maxRestarts != 0 && count >= maxRestarts {
+			return false
+		}
+		if s.restartedCount.CompareAndSwap(count, count+1) {
+			return true
+		}
 	}
+}
+
+// GetRestartedCount is how many panicked containers replaced by restart policy
+func (s *Status) GetRestartedCount() uint64 {
+	return s.restartedCount.Load()
+}
 
-	s.containerIndex++
+// GetStartedCount is how many containers started, it is the latest containerIndex so wrap to 1 after math.MaxUint64
+func (s *Status) GetStartedCount() uint64 {
+	return s.containerIndex.Load()
+}
 
-	return s.containerIndex
+// initQueueDepths must be called before the pool start
+func (s *Status) initQueueDepths(priorityLevels int) {
+	s.queueDepths = make([]atomic.Uint64, priorityLevels)
+}
+func (s *Status) incrQueueDepth(priority int) {
+	s.queueDepths[priority].Add(1)
+}
+func (s *Status) decrQueueDepth(priority int) {
+	s.queueDepths[priority].Add(^uint64(0))
 }
 
-func (s *Status) newContainerBreaker() *bool {
-	return new(bool)
+// GetQueueDepth is how many tasks of priority waiting in queue(task pool only)
+func (s *Status) GetQueueDepth(priority int) uint64 {
+	if priority < 0 || priority >= len(s.queueDepths) {
+		return 0
+	}
+	return s.queueDepths[priority].Load()
+}
+
+// GetQueueDepths is how many tasks waiting in queue for every priority, index is priority(task pool only)
+func (s *Status) GetQueueDepths() []uint64 {
+	depths := make([]uint64, len(s.queueDepths))
+	for i := range s.queueDepths {
+		depths[i] = s.queueDepths[i].Load()
+	}
+	return depths
+}
+
+func (s *Status) newContainerIndex() uint64 {
+	for {
+		index := s.containerIndex.Load()
+
+		next := index + 1
+		if index == math.MaxUint64 {
+			next = 1
+		}
+
+		if s.containerIndex.CompareAndSwap(index, next) {
+			return next
+		}
+	}
 }
 
 func (s *Status) PoolManager() *Status {
@@ -0,0 +1,130 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+var (
+	TestStatusNowRunningCountNotZero   = errors.New("now running count should be zero after same incr and decr")
+	TestStatusContainerIndexNotUnique  = errors.New("container index should be unique")
+	TestStatusExpectRunningCountBelow0 = errors.New("expect running count should not below 0")
+)
+
+func TestStatus_concurrent(t *testing.T) {
+
+	var (
+		s       = newStatus()
+		wg      = sync.WaitGroup{}
+		indexes = sync.Map{}
+	)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				s.incrNowRunningCount()
+				if _, loaded := indexes.LoadOrStore(s.newContainerIndex(), struct{}{}); loaded {
+					t.Error(TestStatusContainerIndexNotUnique)
+				}
+				_ = s.SetExpectRunningCount(uint64(j))
+				_ = s.GetExpectRunningCount()
+				s.decrExpectRunningCount()
+				s.decrNowRunningCount()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.GetNowRunningCount() != 0 {
+		t.Fatal(TestStatusNowRunningCountNotZero)
+	}
+
+	_ = s.SetExpectRunningCount(0)
+	s.decrExpectRunningCount()
+	if s.GetExpectRunningCount() != 0 {
+		t.Fatal(TestStatusExpectRunningCountBelow0)
+	}
+
+}
+
+// mutexStatus is how Status counted before, compare with the lock free Status
+type mutexStatus struct {
+	nowRunningCount      uint64
+	nowRunningCountMutex sync.Mutex
+	containerIndex       uint64
+	containerIndexMutex  sync.Mutex
+}
+
+func (s *mutexStatus) incrNowRunningCount() {
+	s.nowRunningCountMutex.Lock()
+	defer s.nowRunningCountMutex.Unlock()
+	s.nowRunningCount++
+}
+func (s *mutexStatus) decrNowRunningCount() {
+	s.nowRunningCountMutex.Lock()
+	defer s.nowRunningCountMutex.Unlock()
+	s.nowRunningCount--
+}
+func (s *mutexStatus) newContainerIndex() uint64 {
+	s.containerIndexMutex.Lock()
+	defer s.containerIndexMutex.Unlock()
+	s.containerIndex++
+	return s.containerIndex
+}
+
+// container start and exit: new index, incr and decr running count
+func BenchmarkStatus_containerStartExit(b *testing.B) {
+	s := newStatus()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.newContainerIndex()
+			s.incrNowRunningCount()
+			_ = s.GetNowRunningCount()
+			s.decrNowRunningCount()
+		}
+	})
+}
+
+func BenchmarkMutexStatus_containerStartExit(b *testing.B) {
+	s := new(mutexStatus)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			s.newContainerIndex()
+			s.incrNowRunningCount()
+			s.nowRunningCountMutex.Lock()
+			_ = s.nowRunningCount
+			s.nowRunningCountMutex.Unlock()
+			s.decrNowRunningCount()
+		}
+	})
+}
+
+// thousands of containers start and exit
+func BenchmarkPool_containerChurn(b *testing.B) {
+	var (
+		wg = sync.WaitGroup{}
+	)
+	wg.Add(b.N)
+
+	p, err := NewPool(
+		1000,
+		func(containerIndex uint64) {
+			if containerIndex <= uint64(b.N) {
+				wg.Done()
+			}
+		},
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	wg.Wait()
+	b.StopTimer()
+
+	_ = p.Shutdown(context.Background())
+}
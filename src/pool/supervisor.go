@@ -0,0 +1,233 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// supervisor keep the pool's running containers count as expected.
+// it is woken up by signals: expect running count changed, container exited and shutdown,
+// DetectExpectDuration is only a safety net.
+type supervisor struct {
+	*Status
+	*lifecycle
+	*options
+
+	containers *containerRegistry
+	slots      *slotQueue
+
+	containerExitedSignal chan struct{}
+
+	// runningChanged is closed and replaced after revise or a container exited, wake SetExpectRunningCountAndWait.
+	// only replaced if someone waiting it
+	runningChanged       chan struct{}
+	runningChangedWaited bool
+	runningChangedMutex  sync.Mutex
+
+	// revisedTargetRunningCount is the target of last revise, for EventScaledUp and EventScaledDown
+	revisedTargetRunningCount uint64
+
+	// containerStart run container c on its own goroutine, must call containerExit when c end
+	containerStart func(c *container)
+}
+
+func newSupervisor(expectRunningCount uint64, opts []Option) (s *supervisor, err error) {
+	s = new(supervisor)
+	s.Status = newStatus()
+	s.lifecycle = newLifecycle()
+	s.options = newOptions(opts)
+	s.containers = newContainerRegistry()
+	s.slots = newSlotQueue()
+	s.containerExitedSignal = make(chan struct{}, 1)
+	s.runningChanged = make(chan struct{})
+
+	s.setCrashLoopDetection(s.options.crashLoopDetection)
+
+	// set default revise  running count
+	err = s.SetDetectExpectDuration(defaultDetectExpectDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	// set expect running  count
+	err = s.SetExpectRunningCount(expectRunningCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *supervisor) start() {
+	s.supervisorWaitGroup.Add(1)
+	go s.reviseContainerRunningCountAsExpectCount()
+}
+
+func (s *supervisor) reviseContainerRunningCountAsExpectCount() {
+	defer s.supervisorWaitGroup.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		if s.isShutdown() {
+			return
+		}
+
+		wait := s.revise()
+
+		// safety net, and wake up when backoff end
+		detectExpectDuration := s.GetDetectExpectDuration()
+		if wait == 0 || detectExpectDuration != 0 && detectExpectDuration < wait {
+			wait = detectExpectDuration
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer.Reset(wait)
+			timeout = timer.C
+		}
+
+		select {
+		case <-s.expectChangedSignal:
+		case <-s.containerExitedSignal:
+		case <-timeout:
+		case <-s.shutdownSignal:
+			return
+		}
+	}
+}
+
+// revise cancel surplus containers and start needed containers,
+// return how long to wait if needed containers are in backoff.
+func (s *supervisor) revise() (wait time.Duration) {
+	defer s.broadcastRunningChanged()
+
+	expectRunningCount := s.getTargetRunningCount()
+
+	if expectRunningCount != s.revisedTargetRunningCount {
+		eventType := EventScaledUp
+		if expectRunningCount < s.revisedTargetRunningCount {
+			eventType = EventScaledDown
+		}
+		s.events.Publish(Event{Type: eventType, From: s.revisedTargetRunningCount, To: expectRunningCount})
+		s.revisedTargetRunningCount = expectRunningCount
+	}
+
+	// if active containers > GetExpectRunningCount() then cancel surplus containers
+	s.containers.retireSurplus(expectRunningCount, s.scaleDownPolicy)
+
+	// if GetNowRunningCount() < GetExpectRunningCount() then create containers
+	for {
+		nowRunningCount := s.GetNowRunningCount()
+		if nowRunningCount >= expectRunningCount {
+			return 0
+		}
+
+		slot, wait := s.slots.take(expectRunningCount - nowRunningCount)
+		if slot == nil {
+			// every needed slot is in backoff
+			return wait
+		}
+
+		// count it before the goroutine start, so next loop see it
+		s.incrNowRunningCount()
+		s.containerWaitGroup.Add(1)
+		c := s.containers.add(s.ctx, s.newContainerIndex(), slot)
+		s.events.Publish(Event{Type: EventContainerStarted, ContainerIndex: c.index, StartedAt: c.startedAt})
+		go s.containerStart(c)
+	}
+}
+
+// SetExpectRunningCountAndWait set expect running count and wait until running count reach it(0 while paused),
+// return ctx.Err() if ctx done first.
+func (s *supervisor) SetExpectRunningCountAndWait(ctx context.Context, count uint64) error {
+	err := s.SetExpectRunningCount(count)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// take the signal before check, so a change after check is not missed
+		changed := s.runningChangedSignal()
+		if s.GetNowRunningCount() == s.getTargetRunningCount() {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// runningChangedSignal return a channel closed when running count may changed
+func (s *supervisor) runningChangedSignal() <-chan struct{} {
+	s.runningChangedMutex.Lock()
+	defer s.runningChangedMutex.Unlock()
+
+	s.runningChangedWaited = true
+	return s.runningChanged
+}
+
+// broadcastRunningChanged wake every SetExpectRunningCountAndWait
+func (s *supervisor) broadcastRunningChanged() {
+	s.runningChangedMutex.Lock()
+	defer s.runningChangedMutex.Unlock()
+
+	if !s.runningChangedWaited {
+		return
+	}
+	close(s.runningChanged)
+	s.runningChanged = make(chan struct{})
+	s.runningChangedWaited = false
+}
+
+// containerExit must be called on c's goroutine when c end by reason
+func (s *supervisor) containerExit(c *container, reason ExitReason) {
+	if reason == ExitPanic {
+		s.events.Publish(Event{Type: EventContainerPanicked, ContainerIndex: c.index, StartedAt: c.startedAt})
+	}
+
+	retired := s.containers.remove(c)
+	if reason == ExitNormal && retired {
+		reason = ExitRetired
+	}
+	if reason == ExitNormal && s.isShutdown() {
+		reason = ExitShutdown
+	}
+
+	s.containerExiting(c, reason)
+
+	lifetime := time.Since(c.startedAt)
+	s.observeContainerLifetime(lifetime)
+
+	// must before decrNowRunningCount, otherwise the supervisor may replace a given up container
+	// or replace the container without backoff
+	if containerExited(s.Status, s.options, reason, lifetime) {
+		s.slots.release(c.slot, s.backoff, reason, lifetime)
+	}
+
+	s.decrNowRunningCount()
+	s.broadcastRunningChanged()
+	s.events.Publish(Event{Type: EventContainerExited, ContainerIndex: c.index, StartedAt: c.startedAt, Reason: reason})
+	s.containerWaitGroup.Done()
+
+	notify(s.containerExitedSignal)
+}
+
+// notify send to signal without block, signal's capacity should be 1
+func notify(signal chan struct{}) {
+	select {
+	case signal <- struct{}{}:
+	default:
+	}
+}
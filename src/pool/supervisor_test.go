@@ -0,0 +1,166 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	TestSupervisorNotReplacedImmediately = errors.New("exited container not replaced immediately")
+	TestSupervisorNotScaledImmediately   = errors.New("pool not scaled immediately")
+	TestSupervisorPauseNotWork           = errors.New("pause or resume not work")
+)
+
+func TestSupervisor_replaceImmediately(t *testing.T) {
+
+	var (
+		executedCount uint64 = 0
+		mutex                = sync.Mutex{}
+	)
+
+	p, err := NewPool(
+		1,
+		func(containerIndex uint64) {
+			mutex.Lock()
+			executedCount++
+			mutex.Unlock()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	// safety net only
+	err = p.SetDetectExpectDuration(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// polling every hour never reach it before deadline
+	deadline := time.Now().Add(time.Second * 10)
+	for {
+		mutex.Lock()
+		executed := executedCount
+		mutex.Unlock()
+		if executed >= 10 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(TestSupervisorNotReplacedImmediately)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func TestSupervisor_scaleImmediately(t *testing.T) {
+
+	p, err := NewBuildInLoopPoolWithContext(
+		0,
+		func(ctx context.Context, containerEnd func(), containerIndex uint64) {
+			<-ctx.Done()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+	// never detect
+	err = p.SetDetectExpectDuration(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitRunningCount := func(count uint64) {
+		deadline := time.Now().Add(time.Second * 5)
+		for p.GetNowRunningCount() != count {
+			if time.Now().After(deadline) {
+				t.Fatal(TestSupervisorNotScaledImmediately)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	err = p.SetExpectRunningCount(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitRunningCount(100)
+
+	err = p.SetExpectRunningCount(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitRunningCount(10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func TestSupervisor_pause(t *testing.T) {
+
+	p, err := NewTaskPool(5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	p.Pause()
+	err = p.SetExpectRunningCountAndWait(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.GetNowRunningCount() != 0 || p.GetExpectRunningCount() != 5 || !p.IsPaused() {
+		t.Fatal(TestSupervisorPauseNotWork)
+	}
+
+	// queued while paused
+	done := make(chan struct{})
+	err = p.Submit(func() {
+		close(done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.Resume()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal(TestSupervisorPauseNotWork)
+	}
+	err = p.SetExpectRunningCountAndWait(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// paused pool still drain queued tasks when Shutdown
+	p.Pause()
+	err = p.Submit(func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
@@ -0,0 +1,326 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// task run in a container, ctx is the container's ctx
+type task func(ctx context.Context, containerIndex uint64)
+
+type taskPool struct {
+	*pool
+
+	queue   *taskQueue
+	delayed *delayedTasks
+
+	// pendingWaitGroup count submitted but not executed tasks, Shutdown wait them
+	pendingWaitGroup sync.WaitGroup
+	closed           bool
+	closedMutex      sync.RWMutex
+	closedSignal     chan struct{}
+}
+
+var (
+	ErrQueueFull       = errors.New("task queue is full")
+	ErrPoolClosed      = errors.New("pool is closed")
+	ErrInvalidPriority = errors.New("priority out of range")
+)
+
+// OverflowPolicy decide what Submit do when the task queue is full.
+type OverflowPolicy int
+
+const (
+	OverflowBlock    OverflowPolicy = iota // wait until the queue has room(or ctx done when SubmitContext)
+	OverflowFailFast                       // return ErrQueueFull immediately
+)
+
+// WithPriorityLevels set how many priority levels task pool has, priority 0 is the lowest, levels-1 is the highest.
+// default is 1(no priority), levels less than 1 is ignored.
+func WithPriorityLevels(levels int) Option {
+	return func(o *options) {
+		if levels < 1 {
+			return
+		}
+		o.priorityLevels = levels
+	}
+}
+
+// WithPriorityAging raise a queued task's priority 1 every interval it waited,
+// so low priority tasks still make progress. default is no aging.
+func WithPriorityAging(interval time.Duration) Option {
+	return func(o *options) {
+		o.priorityAgingInterval = interval
+	}
+}
+
+// WithOverflowPolicy set what task pool's Submit do when the task queue is full, default is OverflowBlock.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(o *options) {
+		o.overflowPolicy = policy
+	}
+}
+
+func newTaskPool(
+	expectRunningCount uint64,
+	queueSize uint64,
+	opts ...Option,
+) (p *taskPool, err error) {
+
+	if queueSize == 0 {
+		return nil, newTaskPoolQueueSizeIsZero
+	}
+
+	p = new(taskPool)
+	p.closedSignal = make(chan struct{})
+
+	p.pool, err = newPoolWithoutStart(expectRunningCount, p.containerRun, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.initQueueDepths(p.priorityLevels)
+	p.queue = newTaskQueue(queueSize, p.priorityLevels, p.priorityAgingInterval, p.Status)
+	p.delayed = newDelayedTasks()
+
+	p.start()
+
+	// put due delayed tasks into queue
+	p.supervisorWaitGroup.Add(1)
+	go p.releaseDelayedTasks()
+
+	return p, nil
+}
+
+var (
+	newTaskPoolQueueSizeIsZero = errors.New("queue size is 0,no task can be submitted")
+)
+
+// containerRun take tasks from queue until the container's ctx canceled
+func (p *taskPool) containerRun(ctx context.Context, containerIndex uint64) {
+	c := containerFromContext(ctx)
+
+	// waiting task is idle
+	c.busy.Store(false)
+
+	for {
+		t, ok := p.queue.pop(ctx)
+		if !ok {
+			return
+		}
+
+		c.busy.Store(true)
+		p.execute(ctx, containerIndex, t)
+		c.busy.Store(false)
+	}
+}
+
+func (p *taskPool) execute(ctx context.Context, containerIndex uint64, t task) {
+	defer p.pendingWaitGroup.Done()
+
+	start := time.Now()
+	t(ctx, containerIndex)
+	p.observeRunDuration(time.Since(start))
+}
+
+// accept count t in pending tasks, return ErrPoolClosed if the pool closed
+func (p *taskPool) accept(priority int) error {
+	if priority < 0 || priority >= p.priorityLevels {
+		return ErrInvalidPriority
+	}
+
+	p.closedMutex.RLock()
+	defer p.closedMutex.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.pendingWaitGroup.Add(1)
+
+	return nil
+}
+
+// submit put t into queue, drop is called if t queued but dropped without run, nil drop is fine.
+// drop is not called when submit return an error.
+func (p *taskPool) submit(ctx context.Context, priority int, t task, drop func(err error)) error {
+	err := p.accept(priority)
+	if err != nil {
+		return err
+	}
+
+	return p.enqueue(ctx, priority, t, drop, p.overflowPolicy == OverflowFailFast)
+}
+
+// enqueue put accepted t into queue
+func (p *taskPool) enqueue(ctx context.Context, priority int, t task, drop func(err error), failFast bool) error {
+	err := p.queue.push(ctx, t, drop, priority, failFast, p.closedSignal)
+	if err != nil {
+		p.pendingWaitGroup.Done()
+		return err
+	}
+
+	return nil
+}
+
+// Submit put task into queue, containers execute it.
+// when the queue is full, block or return ErrQueueFull by OverflowPolicy.
+func (p *taskPool) Submit(task func()) error {
+	return p.SubmitContext(context.Background(), task)
+}
+
+// SubmitContext same as Submit, but return ctx.Err() if ctx done before the task put into queue.
+func (p *taskPool) SubmitContext(ctx context.Context, task func()) error {
+	return p.SubmitPriorityContext(ctx, 0, task)
+}
+
+// SubmitPriority same as Submit, tasks with higher priority dequeue first.
+// priority must in [0, levels) set by WithPriorityLevels, otherwise return ErrInvalidPriority.
+func (p *taskPool) SubmitPriority(priority int, task func()) error {
+	return p.SubmitPriorityContext(context.Background(), priority, task)
+}
+
+// SubmitPriorityContext same as SubmitPriority, but return ctx.Err() if ctx done before the task put into queue.
+func (p *taskPool) SubmitPriorityContext(ctx context.Context, priority int, task func()) error {
+	if task == nil {
+		return submitTaskIsNil
+	}
+
+	return p.submit(ctx, priority, func(ctx context.Context, containerIndex uint64) {
+		task()
+	}, nil)
+}
+
+var (
+	submitTaskIsNil = errors.New("task is nil")
+)
+
+// SubmitContextWithDrop same as SubmitContext, but dropped is called with ErrPoolClosed
+// if the task queued but dropped without run, e.g. Shutdown ctx done before queued tasks executed.
+// dropped is not called if SubmitContextWithDrop return an error.
+func (p *taskPool) SubmitContextWithDrop(ctx context.Context, task func(), dropped func(err error)) error {
+	if task == nil {
+		return submitTaskIsNil
+	}
+
+	return p.submit(ctx, 0, func(ctx context.Context, containerIndex uint64) {
+		task()
+	}, dropped)
+}
+
+// SubmitAt put task into queue at the time, it does not take a container while waiting.
+// tasks not due yet are dropped when Shutdown.
+func (p *taskPool) SubmitAt(at time.Time, task func()) error {
+	if task == nil {
+		return submitTaskIsNil
+	}
+
+	// hold the lock until added, so Shutdown would not miss it
+	p.closedMutex.RLock()
+	defer p.closedMutex.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+	p.pendingWaitGroup.Add(1)
+
+	p.delayed.add(at, 0, func(ctx context.Context, containerIndex uint64) {
+		task()
+	})
+
+	return nil
+}
+
+// SubmitAfter put task into queue after the duration, same as SubmitAt(time.Now().Add(d), task)
+func (p *taskPool) SubmitAfter(d time.Duration, task func()) error {
+	return p.SubmitAt(time.Now().Add(d), task)
+}
+
+// GetDelayedLength is how many tasks submitted by SubmitAt or SubmitAfter not due yet
+func (p *taskPool) GetDelayedLength() uint64 {
+	return p.delayed.length()
+}
+
+func (p *taskPool) releaseDelayedTasks() {
+	defer p.supervisorWaitGroup.Done()
+
+	for {
+		dt, wait := p.delayed.popDue(time.Now())
+		if dt != nil {
+			// due tasks wait room even if OverflowFailFast, they are accepted already.
+			// error means the pool is closed, the task is dropped
+			_ = p.enqueue(p.ctx, dt.priority, dt.task, nil, false)
+			continue
+		}
+
+		// no task, wait until one added
+		timer := time.NewTimer(time.Hour)
+		if wait > 0 {
+			timer.Reset(wait)
+		}
+
+		select {
+		case <-timer.C:
+		case <-p.delayed.wake:
+		case <-p.shutdownSignal:
+			timer.Stop()
+			return
+		}
+		timer.Stop()
+	}
+}
+
+func (p *taskPool) dropQueued() {
+	for {
+		qt, ok := p.queue.tryPop()
+		if !ok {
+			return
+		}
+		if qt.drop != nil {
+			qt.drop(ErrPoolClosed)
+		}
+		p.pendingWaitGroup.Done()
+	}
+}
+
+// GetQueueLength is how many tasks waiting in queue
+func (p *taskPool) GetQueueLength() uint64 {
+	return p.queue.length()
+}
+
+// Shutdown stop accepting tasks, wait queued tasks executed, then shutdown the pool.
+// if ctx done first, return ctx.Err().
+func (p *taskPool) Shutdown(ctx context.Context) error {
+	p.closedMutex.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.closedSignal)
+	}
+	p.closedMutex.Unlock()
+
+	// not due delayed tasks are dropped
+	for i := p.delayed.clear(); i > 0; i-- {
+		p.pendingWaitGroup.Done()
+	}
+
+	// paused pool has no container to drain queued tasks
+	p.Resume()
+
+	drained := make(chan struct{})
+	go func() {
+		p.pendingWaitGroup.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		// still stop containers, queued tasks are dropped
+		_ = p.pool.Shutdown(ctx)
+		p.dropQueued()
+		return ctx.Err()
+	}
+
+	return p.pool.Shutdown(ctx)
+}
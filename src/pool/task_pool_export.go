@@ -0,0 +1,11 @@
+package pool
+
+// NewTaskPool create a pool keep expectRunningCount containers execute submitted tasks,
+// queueSize is how many tasks can wait in queue.
+func NewTaskPool(
+	expectRunningCount uint64,
+	queueSize uint64,
+	opts ...Option,
+) (p *taskPool, err error) {
+	return newTaskPool(expectRunningCount, queueSize, opts...)
+}
@@ -0,0 +1,212 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	TestTaskPoolExecutedCountNotMatch = errors.New("executed tasks count not match submitted count")
+)
+
+func TestNewTaskPool(t *testing.T) {
+
+	var (
+		executedCount uint64 = 0
+		mutex                = sync.Mutex{}
+	)
+
+	p, err := NewTaskPool(10, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	for i := 0; i < 1000; i++ {
+		err = p.Submit(func() {
+			mutex.Lock()
+			executedCount++
+			mutex.Unlock()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// shutdown wait queued tasks executed
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if executedCount != 1000 {
+		t.Fatal(TestTaskPoolExecutedCountNotMatch)
+	}
+
+	err = p.Submit(func() {})
+	if err != ErrPoolClosed {
+		t.Fatal(err)
+	}
+
+	err = p.Submit(nil)
+	if err != submitTaskIsNil {
+		t.Fatal(err)
+	}
+
+}
+
+func TestTaskPool_Submit_overflowPolicy(t *testing.T) {
+
+	block := make(chan struct{})
+	defer close(block)
+
+	// fail fast
+	{
+		p, err := NewTaskPool(0, 1, WithOverflowPolicy(OverflowFailFast))
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+
+		err = p.Submit(func() {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = p.Submit(func() {})
+		if err != ErrQueueFull {
+			t.Fatal(err)
+		}
+		if p.GetQueueLength() != 1 {
+			t.Fatal(TestTaskPoolExecutedCountNotMatch)
+		}
+	}
+
+	// block, honor ctx
+	{
+		p, err := NewTaskPool(0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shutdownAtCleanup(t, p)
+
+		err = p.Submit(func() {})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		err = p.SubmitContext(ctx, func() {})
+		if err != context.DeadlineExceeded {
+			t.Fatal(err)
+		}
+
+		// blocked submit return when shutdown
+		go func() {
+			time.Sleep(time.Millisecond * 10)
+			ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+			defer cancel()
+			_ = p.Shutdown(ctx)
+		}()
+		err = p.Submit(func() {})
+		if err != ErrPoolClosed {
+			t.Fatal(err)
+		}
+	}
+
+}
+
+func TestTaskPool_SubmitPriority(t *testing.T) {
+
+	p, err := NewTaskPool(0, 10, WithPriorityLevels(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	err = p.SubmitPriority(1, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.SubmitPriority(2, func() {})
+	if err != ErrInvalidPriority {
+		t.Fatal(err)
+	}
+	err = p.SubmitPriority(-1, func() {})
+	if err != ErrInvalidPriority {
+		t.Fatal(err)
+	}
+
+	if p.GetQueueDepth(1) != 1 || p.GetQueueDepth(0) != 0 || p.GetQueueLength() != 1 {
+		t.Fatal(TestTaskPoolExecutedCountNotMatch)
+	}
+
+	_, err = NewTaskPool(1, 0)
+	if err != newTaskPoolQueueSizeIsZero {
+		t.Fatal(err)
+	}
+
+}
+
+var (
+	TestTaskPoolSubmitAfterExecutedTooEarly = errors.New("delayed task executed before due")
+)
+
+func TestTaskPool_SubmitAfter(t *testing.T) {
+
+	p, err := NewTaskPool(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shutdownAtCleanup(t, p)
+
+	var (
+		submittedAt = time.Now()
+		executed    = make(chan time.Time, 2)
+	)
+
+	err = p.SubmitAfter(time.Millisecond*50, func() {
+		executed <- time.Now()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.SubmitAt(submittedAt.Add(time.Millisecond*20), func() {
+		executed <- time.Now()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.GetDelayedLength() != 2 {
+		t.Fatal(TestTaskPoolExecutedCountNotMatch)
+	}
+
+	first, second := <-executed, <-executed
+	if first.Sub(submittedAt) < time.Millisecond*20 || second.Sub(submittedAt) < time.Millisecond*50 {
+		t.Fatal(TestTaskPoolSubmitAfterExecutedTooEarly)
+	}
+
+	// not due tasks dropped when shutdown
+	err = p.SubmitAfter(time.Hour, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.SubmitAfter(time.Millisecond, func() {})
+	if err != ErrPoolClosed {
+		t.Fatal(err)
+	}
+
+}
@@ -0,0 +1,140 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type queuedTask struct {
+	task       task
+	drop       func(err error) // optional, called if the task is dropped without run
+	priority   int
+	enqueuedAt time.Time
+}
+
+// taskQueue is a bounded queue with priority levels, higher priority dequeue first.
+// with aging, a task's priority raise 1 every agingInterval it waited, so low priority tasks still make progress.
+type taskQueue struct {
+	mutex  sync.Mutex
+	levels [][]*queuedTask // index is priority
+
+	rooms chan struct{} // a token per taken room, full means queue is full
+	ready chan struct{} // a token per queued task
+
+	agingInterval time.Duration // 0 means no aging
+
+	status *Status
+}
+
+func newTaskQueue(size uint64, priorityLevels int, agingInterval time.Duration, status *Status) *taskQueue {
+	q := new(taskQueue)
+	q.levels = make([][]*queuedTask, priorityLevels)
+	q.rooms = make(chan struct{}, size)
+	q.ready = make(chan struct{}, size)
+	q.agingInterval = agingInterval
+	q.status = status
+	return q
+}
+
+// push put t into queue, failFast return ErrQueueFull when the queue is full,
+// otherwise wait room until closedSignal closed or ctx done.
+// drop is kept with t, it is nil if nobody care about a dropped t.
+func (q *taskQueue) push(ctx context.Context, t task, drop func(err error), priority int, failFast bool, closedSignal <-chan struct{}) error {
+	if failFast {
+		select {
+		case q.rooms <- struct{}{}:
+		default:
+			return ErrQueueFull
+		}
+	} else {
+		select {
+		case q.rooms <- struct{}{}:
+		case <-closedSignal:
+			return ErrPoolClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	q.mutex.Lock()
+	q.levels[priority] = append(q.levels[priority], &queuedTask{
+		task:       t,
+		drop:       drop,
+		priority:   priority,
+		enqueuedAt: time.Now(),
+	})
+	q.status.incrQueueDepth(priority)
+	q.mutex.Unlock()
+
+	// never block, ready's capacity same as rooms
+	q.ready <- struct{}{}
+
+	return nil
+}
+
+// pop wait a task until ctx done
+func (q *taskQueue) pop(ctx context.Context) (t task, ok bool) {
+	select {
+	case <-q.ready:
+		return q.take().task, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// tryPop take a task with its drop if any without wait
+func (q *taskQueue) tryPop() (qt *queuedTask, ok bool) {
+	select {
+	case <-q.ready:
+		return q.take(), true
+	default:
+		return nil, false
+	}
+}
+
+// take must be called after received a ready token
+func (q *taskQueue) take() *queuedTask {
+	q.mutex.Lock()
+
+	var (
+		now      = time.Now()
+		selected = -1
+		best     int64
+	)
+	for priority, level := range q.levels {
+		if len(level) == 0 {
+			continue
+		}
+
+		// the head is the oldest task in its level
+		head := level[0]
+		effective := int64(head.priority)
+		if q.agingInterval > 0 {
+			effective += int64(now.Sub(head.enqueuedAt) / q.agingInterval)
+		}
+
+		if selected == -1 ||
+			effective > best ||
+			effective == best && head.enqueuedAt.Before(q.levels[selected][0].enqueuedAt) {
+			selected = priority
+			best = effective
+		}
+	}
+
+	head := q.levels[selected][0]
+	q.levels[selected][0] = nil
+	q.levels[selected] = q.levels[selected][1:]
+	q.status.decrQueueDepth(selected)
+
+	q.mutex.Unlock()
+
+	// release the room
+	<-q.rooms
+
+	return head
+}
+
+func (q *taskQueue) length() uint64 {
+	return uint64(len(q.ready))
+}
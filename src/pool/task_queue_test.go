@@ -0,0 +1,115 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	TestTaskQueueOrderNotMatch = errors.New("dequeue order not match priority")
+	TestTaskQueueDepthNotMatch = errors.New("queue depth not match")
+)
+
+func TestTaskQueue_priority(t *testing.T) {
+
+	var (
+		s      = new(Status)
+		q      *taskQueue
+		popped []int
+	)
+	s.initQueueDepths(3)
+	q = newTaskQueue(10, 3, 0, s)
+
+	for _, priority := range []int{0, 2, 1, 2} {
+		priority := priority
+		err := q.push(context.Background(), func(ctx context.Context, containerIndex uint64) {
+			popped = append(popped, priority)
+		}, nil, priority, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s.GetQueueDepth(0) != 1 || s.GetQueueDepth(1) != 1 || s.GetQueueDepth(2) != 2 {
+		t.Fatal(TestTaskQueueDepthNotMatch)
+	}
+
+	for q.length() > 0 {
+		qt, _ := q.tryPop()
+		qt.task(context.Background(), 0)
+	}
+
+	for i, priority := range []int{2, 2, 1, 0} {
+		if popped[i] != priority {
+			t.Fatal(TestTaskQueueOrderNotMatch)
+		}
+	}
+	for _, depth := range s.GetQueueDepths() {
+		if depth != 0 {
+			t.Fatal(TestTaskQueueDepthNotMatch)
+		}
+	}
+
+}
+
+func TestTaskQueue_aging(t *testing.T) {
+
+	var (
+		s      = new(Status)
+		q      *taskQueue
+		popped []int
+	)
+	s.initQueueDepths(3)
+	q = newTaskQueue(10, 3, time.Millisecond*10, s)
+
+	push := func(priority int) {
+		err := q.push(context.Background(), func(ctx context.Context, containerIndex uint64) {
+			popped = append(popped, priority)
+		}, nil, priority, true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// low priority waited long enough to beat high priority
+	push(0)
+	time.Sleep(time.Millisecond * 50)
+	push(2)
+
+	for q.length() > 0 {
+		qt, _ := q.tryPop()
+		qt.task(context.Background(), 0)
+	}
+
+	if popped[0] != 0 || popped[1] != 2 {
+		t.Fatal(TestTaskQueueOrderNotMatch)
+	}
+
+}
+
+func TestTaskQueue_full(t *testing.T) {
+
+	q := newTaskQueue(1, 1, 0, new(Status))
+	q.status.initQueueDepths(1)
+
+	noop := func(ctx context.Context, containerIndex uint64) {}
+
+	err := q.push(context.Background(), noop, nil, 0, true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = q.push(context.Background(), noop, nil, 0, true, nil)
+	if err != ErrQueueFull {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	err = q.push(ctx, noop, nil, 0, false, nil)
+	if err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+}
@@ -0,0 +1,50 @@
+package pool_manager
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+)
+
+// ExpvarName is the suggested name to publish a manager in expvar, e.g. pool_manager.PublishExpvar(pool_manager.ExpvarName).
+const ExpvarName = "goroutine_pool"
+
+var (
+	publishExpvarNameAlreadyBeUsed = errors.New("expvar name already be used")
+
+	// expvar.Publish panic if name used, check and publish atomically
+	publishExpvarMutex sync.Mutex
+)
+
+// Var return an expvar.Var of m, its value is every managed pool's PoolInfo by name,
+// read when /debug/vars requested. publish it with PublishExpvar.
+func (m *Manager) Var() expvar.Var {
+	return expvar.Func(func() any {
+		all := m.All()
+
+		infos := make(map[string]PoolInfo, len(all))
+		for name, s := range all {
+			infos[name] = newPoolInfo(name, s)
+		}
+		return infos
+	})
+}
+
+// PublishExpvar publish m.Var() in expvar with name, so /debug/vars show it.
+// nothing is published until called, return an error if name already published instead of panic.
+func (m *Manager) PublishExpvar(name string) error {
+	publishExpvarMutex.Lock()
+	defer publishExpvarMutex.Unlock()
+
+	if expvar.Get(name) != nil {
+		return publishExpvarNameAlreadyBeUsed
+	}
+	expvar.Publish(name, m.Var())
+
+	return nil
+}
+
+// PublishExpvar publish Default in expvar with name, see Manager.PublishExpvar.
+func PublishExpvar(name string) error {
+	return Default.PublishExpvar(name)
+}
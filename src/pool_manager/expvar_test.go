@@ -0,0 +1,76 @@
+package pool_manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"testing"
+	"time"
+)
+
+var (
+	TestExpvarNotMatch = errors.New("expvar not match")
+)
+
+// expvar can not unpublish, every run(e.g. go test -count) need a new name
+var testManagerVarRuns = 0
+
+func TestManager_Var(t *testing.T) {
+	t.Parallel()
+
+	// importing the package publish nothing
+	if expvar.Get(ExpvarName) != nil {
+		t.Fatal(TestExpvarNotMatch)
+	}
+
+	m := NewManager()
+
+	testManagerVarRuns++
+	name := fmt.Sprintf("test_manager_var_%d", testManagerVarRuns)
+	err := m.PublishExpvar(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expvar.Get(name) == nil {
+		t.Fatal(TestExpvarNotMatch)
+	}
+	err = NewManager().PublishExpvar(name)
+	if err != publishExpvarNameAlreadyBeUsed {
+		t.Fatal(err)
+	}
+
+	p, err := pool.NewPoolWithContext(
+		3,
+		func(ctx context.Context, containerIndex uint64) {
+			<-ctx.Done()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Shutdown(context.Background())
+	if err = m.Add("worker", p); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = p.SetExpectRunningCountAndWait(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	v := m.Var()
+
+	var infos map[string]PoolInfo
+	if err = json.Unmarshal([]byte(v.String()), &infos); err != nil {
+		t.Fatal(err)
+	}
+	info, ok := infos["worker"]
+	if !ok || info.NowRunningCount != 3 || info.StartedCount != 3 || info.ExitedCount != 0 || info.PanickedCount != 0 {
+		t.Fatal(v.String(), TestExpvarNotMatch)
+	}
+
+}
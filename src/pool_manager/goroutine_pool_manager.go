@@ -1,61 +1,245 @@
 package pool_manager
 
 import (
+	"context"
 	"errors"
 	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"sync"
 	"time"
 )
 
+// Interface is what every kind of pool provide, managed pool can be inspected and shutdown by name.
 type Interface interface {
 	PoolManager() *pool.Status
+	Shutdown(ctx context.Context) error
 }
 
+// Manager manage pools by name, zero value is ready to use.
+// create own Manager when u need pools isolated from Default, e.g. tests run in parallel.
+type Manager struct {
+	pools      map[string]managedPool
+	poolsMutex sync.RWMutex
+
+	// events of every managed pool tagged with name
+	events pool.EventBroker
+}
+
+type managedPool struct {
+	Interface
+
+	// stopForwardEvents stop forwarding the pool's events to manager
+	stopForwardEvents func()
+}
+
+// Default is the Manager used by package level functions.
+var Default *Manager
+
 var (
-	pools map[string]*pool.Status
+	addNameAlreadyBeUsed = errors.New("name already be used")
+	addPoolIsNil         = errors.New("pool is nil")
+
+	ErrPoolNotFound = errors.New("pool not found")
 )
 
-var addNameAlreadyBeUsed = errors.New("name already be used")
+func NewManager() *Manager {
+	return &Manager{pools: make(map[string]managedPool)}
+}
 
-func Add(name string, p Interface) error {
-	_, ok := pools[name]
+func (m *Manager) Add(name string, p Interface) error {
+	if p == nil || p.PoolManager() == nil {
+		return addPoolIsNil
+	}
+
+	m.poolsMutex.Lock()
+	defer m.poolsMutex.Unlock()
+
+	_, ok := m.pools[name]
 	if ok {
 		return addNameAlreadyBeUsed
 	}
 
-	pools[name] = p.PoolManager()
+	if m.pools == nil {
+		m.pools = make(map[string]managedPool)
+	}
+	m.pools[name] = managedPool{
+		Interface: p,
+		stopForwardEvents: p.PoolManager().SubscribeFunc(func(e pool.Event) {
+			e.Pool = name
+			m.events.Publish(e)
+		}),
+	}
 
 	return nil
 }
 
-// todo add release
-//func Release(name string) {
-//
-//
-//
-//}
+// Get return the pool added with name.
+func (m *Manager) Get(name string) (Interface, bool) {
+	m.poolsMutex.RLock()
+	defer m.poolsMutex.RUnlock()
+
+	p, ok := m.pools[name]
+	return p.Interface, ok
+}
+
+// Release remove the pool from manager and shutdown it, wait for its containers end of execution.
+// name is free to Add again once Release called.
+func (m *Manager) Release(name string) error {
+	return m.ReleaseContext(context.Background(), name)
+}
+
+// ReleaseContext is Release but return ctx.Err() if ctx done before the pool shutdown,
+// the pool is removed from manager anyway.
+func (m *Manager) ReleaseContext(ctx context.Context, name string) error {
+	m.poolsMutex.Lock()
+	p, ok := m.pools[name]
+	delete(m.pools, name)
+	m.poolsMutex.Unlock()
+
+	if !ok {
+		return ErrPoolNotFound
+	}
+
+	// containers' exit events during shutdown are forwarded
+	err := p.Shutdown(ctx)
+	p.stopForwardEvents()
+	m.events.Publish(pool.Event{Type: pool.EventPoolReleased, Pool: name})
+
+	return err
+}
+
+// Subscribe return a channel receive events of every managed pool, Event.Pool is the pool's name.
+// see pool.EventBroker.Subscribe.
+func (m *Manager) Subscribe(opts ...pool.SubscribeOption) (events <-chan pool.Event, cancel func()) {
+	return m.events.Subscribe(opts...)
+}
+
+func (m *Manager) status(name string) (*pool.Status, error) {
+	p, ok := m.Get(name)
+	if !ok {
+		return nil, ErrPoolNotFound
+	}
+
+	return p.PoolManager(), nil
+}
+
+func (m *Manager) SetExpectRunningCount(name string, count uint64) error {
+	s, err := m.status(name)
+	if err != nil {
+		return err
+	}
+	return s.SetExpectRunningCount(count)
+}
+func (m *Manager) GetExpectRunningCount(name string) (uint64, error) {
+	s, err := m.status(name)
+	if err != nil {
+		return 0, err
+	}
+	return s.GetExpectRunningCount(), nil
+}
+
+func (m *Manager) SetDetectExpectDuration(name string, duration time.Duration) error {
+	s, err := m.status(name)
+	if err != nil {
+		return err
+	}
+	return s.SetDetectExpectDuration(duration)
+}
+func (m *Manager) GetDetectExpectDuration(name string) (time.Duration, error) {
+	s, err := m.status(name)
+	if err != nil {
+		return 0, err
+	}
+	return s.GetDetectExpectDuration(), nil
+}
+
+func (m *Manager) GetNowRunningCount(name string) (uint64, error) {
+	s, err := m.status(name)
+	if err != nil {
+		return 0, err
+	}
+	return s.GetNowRunningCount(), nil
+}
+
+// Health report the pool is healthy, degraded or crash looping.
+func (m *Manager) Health(name string) (pool.Health, error) {
+	s, err := m.status(name)
+	if err != nil {
+		return pool.Healthy, err
+	}
+	return s.Health(), nil
+}
+
+func (m *Manager) Info(name string) (*pool.Status, error) {
+	return m.status(name)
+}
+
+// All return a copy of managed pools' status, safe to range while pools added or released.
+func (m *Manager) All() map[string]*pool.Status {
+	m.poolsMutex.RLock()
+	defer m.poolsMutex.RUnlock()
+
+	all := make(map[string]*pool.Status, len(m.pools))
+	for name, p := range m.pools {
+		all[name] = p.PoolManager()
+	}
+
+	return all
+}
+
+// package level functions delegate to Default
+
+func Add(name string, p Interface) error {
+	return Default.Add(name, p)
+}
+
+// Get return the pool added with name.
+func Get(name string) (Interface, bool) {
+	return Default.Get(name)
+}
+
+// Release remove the pool from manager and shutdown it, wait for its containers end of execution.
+func Release(name string) error {
+	return Default.Release(name)
+}
+
+// ReleaseContext is Release but return ctx.Err() if ctx done before the pool shutdown.
+func ReleaseContext(ctx context.Context, name string) error {
+	return Default.ReleaseContext(ctx, name)
+}
 
 func SetExpectRunningCount(name string, count uint64) error {
-	return pools[name].SetExpectRunningCount(count)
+	return Default.SetExpectRunningCount(name, count)
 }
-func GetExpectRunningCount(name string) uint64 {
-	return pools[name].GetExpectRunningCount()
+func GetExpectRunningCount(name string) (uint64, error) {
+	return Default.GetExpectRunningCount(name)
 }
 
 func SetDetectExpectDuration(name string, duration time.Duration) error {
-	return pools[name].SetDetectExpectDuration(duration)
+	return Default.SetDetectExpectDuration(name, duration)
 }
-func GetDetectExpectDuration(name string) time.Duration {
-	return pools[name].GetDetectExpectDuration()
+func GetDetectExpectDuration(name string) (time.Duration, error) {
+	return Default.GetDetectExpectDuration(name)
 }
 
-func GetNowRunningCount(name string) uint64 {
-	return pools[name].GetNowRunningCount()
+func GetNowRunningCount(name string) (uint64, error) {
+	return Default.GetNowRunningCount(name)
 }
 
-func Info(name string) *pool.Status {
-	return pools[name]
+// Health report the pool is healthy, degraded or crash looping.
+func Health(name string) (pool.Health, error) {
+	return Default.Health(name)
 }
 
+func Info(name string) (*pool.Status, error) {
+	return Default.Info(name)
+}
+
+// All return a copy of managed pools' status.
 func All() map[string]*pool.Status {
-	return pools
+	return Default.All()
+}
+
+// Subscribe return a channel receive events of every pool in Default.
+func Subscribe(opts ...pool.SubscribeOption) (events <-chan pool.Event, cancel func()) {
+	return Default.Subscribe(opts...)
 }
@@ -1 +1,281 @@
 package pool_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	TestHealthNotMatch          = errors.New("health not match pool's health")
+	TestReleasedPoolStillExists = errors.New("released pool still exists")
+	TestReleasedPoolNotShutdown = errors.New("released pool not shutdown")
+	TestUnknownNameNoError      = errors.New("unknown name not return error")
+	TestManagerNotIsolated      = errors.New("managers not isolated")
+	TestEventNotTagged          = errors.New("events not forwarded with pool name")
+)
+
+func TestHealth(t *testing.T) {
+
+	p, err := pool.NewPoolWithContext(
+		1,
+		func(ctx context.Context, containerIndex uint64) {
+			<-ctx.Done()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Add("TestHealth", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Release("TestHealth")
+
+	health, err := Health("TestHealth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health != pool.Healthy {
+		t.Fatal(TestHealthNotMatch)
+	}
+
+	info, err := Info("TestHealth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Health() != p.Health() {
+		t.Fatal(TestHealthNotMatch)
+	}
+
+}
+
+func TestRelease(t *testing.T) {
+
+	p, err := pool.NewPoolWithContext(
+		3,
+		func(ctx context.Context, containerIndex uint64) {
+			<-ctx.Done()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Add("TestRelease", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = Add("TestRelease", p)
+	if err != addNameAlreadyBeUsed {
+		t.Fatal(err)
+	}
+
+	got, ok := Get("TestRelease")
+	if !ok || got != Interface(p) {
+		t.Fatal(ErrPoolNotFound)
+	}
+
+	err = Release("TestRelease")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok = Get("TestRelease"); ok {
+		t.Fatal(TestReleasedPoolStillExists)
+	}
+	if p.GetNowRunningCount() != 0 {
+		t.Fatal(TestReleasedPoolNotShutdown)
+	}
+
+	err = Release("TestRelease")
+	if err != ErrPoolNotFound {
+		t.Fatal(TestUnknownNameNoError)
+	}
+
+}
+
+func TestUnknownName(t *testing.T) {
+
+	const name = "TestUnknownName"
+
+	if err := SetExpectRunningCount(name, 1); err != ErrPoolNotFound {
+		t.Fatal(TestUnknownNameNoError)
+	}
+	if _, err := GetExpectRunningCount(name); err != ErrPoolNotFound {
+		t.Fatal(TestUnknownNameNoError)
+	}
+	if err := SetDetectExpectDuration(name, time.Second); err != ErrPoolNotFound {
+		t.Fatal(TestUnknownNameNoError)
+	}
+	if _, err := GetDetectExpectDuration(name); err != ErrPoolNotFound {
+		t.Fatal(TestUnknownNameNoError)
+	}
+	if _, err := GetNowRunningCount(name); err != ErrPoolNotFound {
+		t.Fatal(TestUnknownNameNoError)
+	}
+	if _, err := Health(name); err != ErrPoolNotFound {
+		t.Fatal(TestUnknownNameNoError)
+	}
+	if _, err := Info(name); err != ErrPoolNotFound {
+		t.Fatal(TestUnknownNameNoError)
+	}
+
+}
+
+func TestConcurrent(t *testing.T) {
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("TestConcurrent%d", i)
+
+			p, err := pool.NewPoolWithContext(
+				1,
+				func(ctx context.Context, containerIndex uint64) {
+					<-ctx.Done()
+				},
+			)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if err = Add(name, p); err != nil {
+				t.Error(err)
+				return
+			}
+			if err = SetExpectRunningCount(name, 2); err != nil {
+				t.Error(err)
+			}
+			_ = All()
+			if err = Release(name); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+}
+
+func TestManager_isolated(t *testing.T) {
+	t.Parallel()
+
+	var (
+		m1 = NewManager()
+		m2 = new(Manager) // zero value ready to use
+	)
+
+	for _, m := range []*Manager{m1, m2} {
+		p, err := pool.NewPoolWithContext(
+			1,
+			func(ctx context.Context, containerIndex uint64) {
+				<-ctx.Done()
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// same name in different managers
+		err = m.Add("TestManager_isolated", p)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok := Get("TestManager_isolated"); ok {
+		t.Fatal(TestManagerNotIsolated)
+	}
+
+	err := m1.Release("TestManager_isolated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m2.Get("TestManager_isolated"); !ok {
+		t.Fatal(TestManagerNotIsolated)
+	}
+	if len(m1.All()) != 0 || len(m2.All()) != 1 {
+		t.Fatal(TestManagerNotIsolated)
+	}
+
+	err = m2.Release("TestManager_isolated")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+func TestManager_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+	events, cancel := m.Subscribe(pool.WithSlowSubscriberPolicy(pool.Buffer))
+	defer cancel()
+
+	p, err := pool.NewPoolWithContext(
+		0,
+		func(ctx context.Context, containerIndex uint64) {
+			<-ctx.Done()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Add("worker", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.SetExpectRunningCount("worker", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for p.GetNowRunningCount() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	err = m.Release("worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// container exited by shutdown is forwarded before the pool released
+	var received []pool.EventType
+	timeout := time.After(time.Second)
+	for len(received) == 0 || received[len(received)-1] != pool.EventPoolReleased {
+		select {
+		case e := <-events:
+			if e.Pool != "worker" {
+				t.Fatal(e, TestEventNotTagged)
+			}
+			received = append(received, e.Type)
+		case <-timeout:
+			t.Fatal(received, TestEventNotTagged)
+		}
+	}
+
+	expect := []pool.EventType{
+		pool.EventExpectChanged,
+		pool.EventScaledUp,
+		pool.EventContainerStarted,
+		pool.EventContainerExited,
+		pool.EventPoolReleased,
+	}
+	if len(received) != len(expect) {
+		t.Fatal(received, TestEventNotTagged)
+	}
+	for i := range expect {
+		if received[i] != expect[i] {
+			t.Fatal(received, TestEventNotTagged)
+		}
+	}
+
+}
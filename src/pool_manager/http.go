@@ -0,0 +1,227 @@
+package pool_manager
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PoolInfo is a pool's status in http api and expvar.
+type PoolInfo struct {
+	Name                 string   `json:"name"`
+	ExpectRunningCount   uint64   `json:"expect_running_count"`
+	NowRunningCount      uint64   `json:"now_running_count"`
+	DetectExpectDuration string   `json:"detect_expect_duration"` // time.Duration string, e.g. "1s"
+	Paused               bool     `json:"paused"`
+	Health               string   `json:"health"`
+	StartedCount         uint64   `json:"started_count"`
+	ExitedCount          uint64   `json:"exited_count"`
+	PanickedCount        uint64   `json:"panicked_count"`
+	RestartedCount       uint64   `json:"restarted_count"`
+	QueueDepths          []uint64 `json:"queue_depths,omitempty"` // task pool only, index is priority
+}
+
+func newPoolInfo(name string, s *pool.Status) PoolInfo {
+	return PoolInfo{
+		Name:                 name,
+		ExpectRunningCount:   s.GetExpectRunningCount(),
+		NowRunningCount:      s.GetNowRunningCount(),
+		DetectExpectDuration: s.GetDetectExpectDuration().String(),
+		Paused:               s.IsPaused(),
+		Health:               s.Health().String(),
+		StartedCount:         s.GetStartedCount(),
+		ExitedCount:          s.GetExitedCount(),
+		PanickedCount:        s.GetPanickedCount(),
+		RestartedCount:       s.GetRestartedCount(),
+		QueueDepths:          s.GetQueueDepths(),
+	}
+}
+
+// ExpectRunningCountRequest is the body of PUT /pools/{name}/expect_running_count
+type ExpectRunningCountRequest struct {
+	Count uint64 `json:"count"`
+}
+
+// DetectExpectDurationRequest is the body of PUT /pools/{name}/detect_expect_duration
+type DetectExpectDurationRequest struct {
+	Duration string `json:"duration"` // time.Duration string, e.g. "500ms"
+}
+
+// ErrorResponse is the body when request failed
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+var (
+	httpNotFound         = errors.New("not found")
+	httpMethodNotAllowed = errors.New("method not allowed")
+)
+
+// httpActionMethods is the method of every /pools/{name}/{action}, empty action accept GET and DELETE
+var httpActionMethods = map[string]string{
+	"":                       "",
+	"release":                http.MethodPost,
+	"expect_running_count":   http.MethodPut,
+	"detect_expect_duration": http.MethodPut,
+	"pause":                  http.MethodPost,
+	"resume":                 http.MethodPost,
+}
+
+// Handler return the http api of m, routes:
+//
+//	GET    /pools                                list pools' PoolInfo sorted by name
+//	GET    /pools/{name}                         the pool's PoolInfo
+//	PUT    /pools/{name}/expect_running_count    body ExpectRunningCountRequest
+//	PUT    /pools/{name}/detect_expect_duration  body DetectExpectDurationRequest
+//	POST   /pools/{name}/pause                   retire every container but keep expect running count
+//	POST   /pools/{name}/resume                  bring paused containers back
+//	DELETE /pools/{name}                         release the pool, same as POST /pools/{name}/release
+//
+// successful requests respond the pool's PoolInfo(release respond 204), failed requests respond ErrorResponse.
+// {name} is path escaped, so a name contain "/" is escaped as %2F.
+// mount it under a prefix with http.StripPrefix.
+func (m *Manager) Handler() http.Handler {
+	return http.HandlerFunc(m.serveHTTP)
+}
+
+// Handler return the http api of Default.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+func (m *Manager) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	// route on escaped path, an escaped "/" in name is not a separator
+	path := strings.Trim(r.URL.EscapedPath(), "/")
+
+	if path == "pools" {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, httpMethodNotAllowed)
+			return
+		}
+		m.serveList(w)
+		return
+	}
+
+	rest, ok := cutPrefix(path, "pools/")
+	if !ok || rest == "" {
+		writeError(w, http.StatusNotFound, httpNotFound)
+		return
+	}
+	escapedName, action, _ := strings.Cut(rest, "/")
+	name, err := url.PathUnescape(escapedName)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s, err := m.status(name)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	method, ok := httpActionMethods[action]
+	if !ok {
+		writeError(w, http.StatusNotFound, httpNotFound)
+		return
+	}
+	if method != "" && r.Method != method ||
+		method == "" && r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, httpMethodNotAllowed)
+		return
+	}
+
+	switch action {
+	case "":
+		if r.Method == http.MethodDelete {
+			m.serveRelease(w, r, name)
+			return
+		}
+	case "release":
+		m.serveRelease(w, r, name)
+		return
+	case "expect_running_count":
+		var req ExpectRunningCountRequest
+		if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err = s.SetExpectRunningCount(req.Count); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	case "detect_expect_duration":
+		var (
+			req      DetectExpectDurationRequest
+			duration time.Duration
+		)
+		if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if duration, err = time.ParseDuration(req.Duration); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err = s.SetDetectExpectDuration(duration); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	case "pause":
+		s.Pause()
+	case "resume":
+		s.Resume()
+	}
+
+	writeJSON(w, http.StatusOK, newPoolInfo(name, s))
+}
+
+func (m *Manager) serveList(w http.ResponseWriter) {
+	all := m.All()
+
+	infos := make([]PoolInfo, 0, len(all))
+	for name, s := range all {
+		infos = append(infos, newPoolInfo(name, s))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// serveRelease wait the pool shutdown until the request canceled, the pool is removed anyway
+func (m *Manager) serveRelease(w http.ResponseWriter, r *http.Request, name string) {
+	err := m.ReleaseContext(r.Context(), name)
+	if err == ErrPoolNotFound {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusGatewayTimeout, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, ErrorResponse{Error: err.Error()})
+}
+
+// cutPrefix is strings.CutPrefix, which need go1.20
+func cutPrefix(s, prefix string) (after string, found bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -0,0 +1,139 @@
+package pool_manager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	TestHTTPStatusCodeNotMatch = errors.New("http status code not match")
+	TestHTTPPoolInfoNotMatch   = errors.New("http pool info not match")
+)
+
+func TestManager_Handler(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+
+	p, err := pool.NewPoolWithContext(
+		2,
+		func(ctx context.Context, containerIndex uint64) {
+			<-ctx.Done()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Add("worker", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.StripPrefix("/admin", m.Handler()))
+	defer server.Close()
+
+	do := func(method string, path string, body string, expectCode int, v any) {
+		req, err := http.NewRequest(method, server.URL+"/admin"+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != expectCode {
+			t.Fatal(method, path, resp.StatusCode, TestHTTPStatusCodeNotMatch)
+		}
+		if v != nil {
+			err = json.NewDecoder(resp.Body).Decode(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	var infos []PoolInfo
+	do(http.MethodGet, "/pools", "", http.StatusOK, &infos)
+	if len(infos) != 1 || infos[0].Name != "worker" || infos[0].ExpectRunningCount != 2 {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+
+	var info PoolInfo
+	do(http.MethodPut, "/pools/worker/expect_running_count", `{"count":5}`, http.StatusOK, &info)
+	if info.ExpectRunningCount != 5 || p.GetExpectRunningCount() != 5 {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+
+	do(http.MethodPut, "/pools/worker/detect_expect_duration", `{"duration":"500ms"}`, http.StatusOK, &info)
+	if info.DetectExpectDuration != "500ms" || p.GetDetectExpectDuration() != time.Millisecond*500 {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+
+	var errResp ErrorResponse
+	do(http.MethodPut, "/pools/worker/detect_expect_duration", `{"duration":"1ns"}`, http.StatusBadRequest, &errResp)
+	if errResp.Error == "" {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+	do(http.MethodPut, "/pools/worker/expect_running_count", `{"count":"a"}`, http.StatusBadRequest, nil)
+
+	do(http.MethodPost, "/pools/worker/pause", "", http.StatusOK, &info)
+	if !info.Paused || !p.IsPaused() {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+	do(http.MethodPost, "/pools/worker/resume", "", http.StatusOK, &info)
+	if info.Paused || p.IsPaused() {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+
+	do(http.MethodGet, "/pools/worker", "", http.StatusOK, &info)
+	if info.Name != "worker" || info.Health != pool.Healthy.String() {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+
+	do(http.MethodGet, "/pools/nobody", "", http.StatusNotFound, &errResp)
+	if errResp.Error != ErrPoolNotFound.Error() {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+	do(http.MethodGet, "/pools/worker/unknown", "", http.StatusNotFound, nil)
+	do(http.MethodGet, "/pools/worker/pause", "", http.StatusMethodNotAllowed, nil)
+	do(http.MethodPost, "/pools", "", http.StatusMethodNotAllowed, nil)
+
+	// name contain "/" is escaped
+	slashed, err := pool.NewTaskPool(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Add("jobs/nightly batch", slashed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	do(http.MethodGet, "/pools/jobs%2Fnightly%20batch", "", http.StatusOK, &info)
+	if info.Name != "jobs/nightly batch" {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+	do(http.MethodPost, "/pools/jobs%2Fnightly%20batch/pause", "", http.StatusOK, &info)
+	if !info.Paused || !slashed.IsPaused() {
+		t.Fatal(TestHTTPPoolInfoNotMatch)
+	}
+	do(http.MethodGet, "/pools/jobs/nightly%20batch", "", http.StatusNotFound, nil)
+	do(http.MethodDelete, "/pools/jobs%2Fnightly%20batch", "", http.StatusNoContent, nil)
+
+	do(http.MethodDelete, "/pools/worker", "", http.StatusNoContent, nil)
+	if _, ok := m.Get("worker"); ok {
+		t.Fatal(TestReleasedPoolStillExists)
+	}
+	if p.GetNowRunningCount() != 0 {
+		t.Fatal(TestReleasedPoolNotShutdown)
+	}
+	do(http.MethodPost, "/pools/worker/release", "", http.StatusNotFound, nil)
+
+}
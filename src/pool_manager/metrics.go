@@ -0,0 +1,138 @@
+package pool_manager
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metric is a family in prometheus text format, value read from a pool's status
+type metric struct {
+	name  string
+	help  string
+	kind  string // gauge or counter
+	value func(s *pool.Status) float64
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var metrics = []metric{
+	{"goroutine_pool_expect_running_count", "Expect running containers count.", "gauge",
+		func(s *pool.Status) float64 { return float64(s.GetExpectRunningCount()) }},
+	{"goroutine_pool_running_count", "Running containers count.", "gauge",
+		func(s *pool.Status) float64 { return float64(s.GetNowRunningCount()) }},
+	{"goroutine_pool_paused", "1 if the pool is paused.", "gauge",
+		func(s *pool.Status) float64 { return boolValue(s.IsPaused()) }},
+	{"goroutine_pool_containers_started_total", "Containers started.", "counter",
+		func(s *pool.Status) float64 { return float64(s.GetStartedCount()) }},
+	{"goroutine_pool_containers_exited_total", "Containers exited, include panicked.", "counter",
+		func(s *pool.Status) float64 { return float64(s.GetExitedCount()) }},
+	{"goroutine_pool_containers_panicked_total", "Containers exited by panic.", "counter",
+		func(s *pool.Status) float64 { return float64(s.GetPanickedCount()) }},
+	{"goroutine_pool_containers_restarted_total", "Panicked containers replaced by restart policy.", "counter",
+		func(s *pool.Status) float64 { return float64(s.GetRestartedCount()) }},
+}
+
+// WriteMetrics write every managed pool's metrics in prometheus text format, pools sorted by name.
+func (m *Manager) WriteMetrics(w io.Writer) error {
+	all := m.All()
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+
+	for _, metric := range metrics {
+		writeMetricHeader(bw, metric.name, metric.help, metric.kind)
+		for _, name := range names {
+			writeSample(bw, metric.name, poolLabel(name), metric.value(all[name]))
+		}
+	}
+
+	stats := make(map[string]pool.Stats, len(all))
+	for _, name := range names {
+		stats[name] = all[name].Stats()
+	}
+
+	writeMetricHeader(bw, "goroutine_pool_run_duration_seconds", "Executions of pool's function, task for task pool, iteration for build in loop pool.", "histogram")
+	for _, name := range names {
+		writeHistogram(bw, "goroutine_pool_run_duration_seconds", name, stats[name].RunDuration)
+	}
+
+	writeMetricHeader(bw, "goroutine_pool_container_lifetime_seconds", "Exited containers from start to exit.", "histogram")
+	for _, name := range names {
+		writeHistogram(bw, "goroutine_pool_container_lifetime_seconds", name, stats[name].ContainerLifetime)
+	}
+
+	writeMetricHeader(bw, "goroutine_pool_queue_depth", "Tasks waiting in queue by priority, task pool only.", "gauge")
+	for _, name := range names {
+		for priority, depth := range all[name].GetQueueDepths() {
+			writeSample(bw, "goroutine_pool_queue_depth", poolLabel(name)+`,priority="`+strconv.Itoa(priority)+`"`, float64(depth))
+		}
+	}
+
+	return bw.Flush()
+}
+
+// MetricsHandler serve WriteMetrics for prometheus to scrape.
+func (m *Manager) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", metricsContentType)
+		_ = m.WriteMetrics(w)
+	})
+}
+
+// WriteMetrics write Default's pools metrics in prometheus text format.
+func WriteMetrics(w io.Writer) error {
+	return Default.WriteMetrics(w)
+}
+
+// MetricsHandler serve Default's pools metrics for prometheus to scrape.
+func MetricsHandler() http.Handler {
+	return Default.MetricsHandler()
+}
+
+func writeMetricHeader(w io.Writer, name string, help string, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeSample(w io.Writer, name string, labels string, value float64) {
+	fmt.Fprintf(w, "%s{%s} %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// writeHistogram write cumulative buckets, sum and count of a pool's histogram
+func writeHistogram(w io.Writer, name string, poolName string, h pool.HistogramSnapshot) {
+	var cumulative uint64
+	for _, bucket := range h.Buckets {
+		cumulative += bucket.Count
+
+		le := "+Inf"
+		if bucket.UpperBound >= 0 {
+			le = strconv.FormatFloat(bucket.UpperBound.Seconds(), 'g', -1, 64)
+		}
+		writeSample(w, name+"_bucket", poolLabel(poolName)+`,le="`+le+`"`, float64(cumulative))
+	}
+	writeSample(w, name+"_sum", poolLabel(poolName), h.Sum.Seconds())
+	writeSample(w, name+"_count", poolLabel(poolName), float64(h.Count))
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func poolLabel(name string) string {
+	return `pool="` + labelValueReplacer.Replace(name) + `"`
+}
@@ -0,0 +1,103 @@
+package pool_manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	TestMetricsNotMatch = errors.New("metrics not match")
+)
+
+func TestManager_WriteMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := NewManager()
+
+	exited := make(chan struct{}, 1)
+	p, err := pool.NewPoolWithContext(
+		2,
+		func(ctx context.Context, containerIndex uint64) {
+			// first container exit immediately, replaced by the third
+			if containerIndex == 1 {
+				exited <- struct{}{}
+				return
+			}
+			<-ctx.Done()
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Shutdown(context.Background())
+	<-exited
+
+	tp, err := pool.NewTaskPool(1, 10, pool.WithPriorityLevels(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tp.Shutdown(context.Background())
+
+	if err = m.Add("loop", p); err != nil {
+		t.Fatal(err)
+	}
+	if err = m.Add(`task"pool`, tp); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait the replacement counted
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for p.GetStartedCount() != 3 || p.GetNowRunningCount() != 2 {
+		if ctx.Err() != nil {
+			t.Fatal(TestMetricsNotMatch)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	buf := new(bytes.Buffer)
+	if err = m.WriteMetrics(buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, expect := range []string{
+		"# TYPE goroutine_pool_expect_running_count gauge\n",
+		`goroutine_pool_expect_running_count{pool="loop"} 2` + "\n",
+		`goroutine_pool_running_count{pool="loop"} 2` + "\n",
+		`goroutine_pool_paused{pool="loop"} 0` + "\n",
+		"# TYPE goroutine_pool_containers_started_total counter\n",
+		`goroutine_pool_containers_started_total{pool="loop"} 3` + "\n",
+		`goroutine_pool_containers_exited_total{pool="loop"} 1` + "\n",
+		`goroutine_pool_containers_panicked_total{pool="loop"} 0` + "\n",
+		"# TYPE goroutine_pool_container_lifetime_seconds histogram\n",
+		`goroutine_pool_container_lifetime_seconds_bucket{pool="loop",le="1e-06"} `,
+		`goroutine_pool_container_lifetime_seconds_bucket{pool="loop",le="+Inf"} 1` + "\n",
+		`goroutine_pool_container_lifetime_seconds_count{pool="loop"} 1` + "\n",
+		`goroutine_pool_container_lifetime_seconds_sum{pool="loop"} `,
+		"# TYPE goroutine_pool_run_duration_seconds histogram\n",
+		`goroutine_pool_run_duration_seconds_count{pool="loop"} 1` + "\n",
+		`goroutine_pool_queue_depth{pool="task\"pool",priority="1"} 0` + "\n",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Fatal(expect, out, TestMetricsNotMatch)
+		}
+	}
+	if strings.Contains(out, `goroutine_pool_queue_depth{pool="loop"`) {
+		t.Fatal(out, TestMetricsNotMatch)
+	}
+
+	recorder := httptest.NewRecorder()
+	m.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Header().Get("Content-Type") != metricsContentType || !strings.Contains(recorder.Body.String(), `pool="loop"`) {
+		t.Fatal(TestMetricsNotMatch)
+	}
+
+}
@@ -0,0 +1,223 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule report the next fire time after t.
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+var (
+	parseSpecFieldsCountError = errors.New("spec need 5 fields: minute hour day-of-month month day-of-week")
+	parseEveryDurationError   = errors.New("@every need a duration >= 1s")
+)
+
+// Parse parse a standard 5 fields cron spec(minute hour day-of-month month day-of-week),
+// or descriptors: @yearly @annually @monthly @weekly @daily @midnight @hourly @every <duration>.
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, err
+		}
+		if d < time.Second {
+			return nil, parseEveryDurationError
+		}
+		return everySchedule{duration: d}, nil
+	}
+
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, parseSpecFieldsCountError
+	}
+
+	var (
+		s   cronSchedule
+		err error
+	)
+	if s.minute, _, err = parseField(fields[0], minuteBounds); err != nil {
+		return nil, err
+	}
+	if s.hour, _, err = parseField(fields[1], hourBounds); err != nil {
+		return nil, err
+	}
+	if s.dayOfMonth, s.dayOfMonthAny, err = parseField(fields[2], dayOfMonthBounds); err != nil {
+		return nil, err
+	}
+	if s.month, _, err = parseField(fields[3], monthBounds); err != nil {
+		return nil, err
+	}
+	if s.dayOfWeek, s.dayOfWeekAny, err = parseField(fields[4], dayOfWeekBounds); err != nil {
+		return nil, err
+	}
+	// 7 is sunday too
+	if s.dayOfWeek&(1<<7) != 0 {
+		s.dayOfWeek |= 1
+	}
+
+	return s, nil
+}
+
+type bounds struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minuteBounds     = bounds{name: "minute", min: 0, max: 59}
+	hourBounds       = bounds{name: "hour", min: 0, max: 23}
+	dayOfMonthBounds = bounds{name: "day-of-month", min: 1, max: 31}
+	monthBounds      = bounds{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dayOfWeekBounds = bounds{name: "day-of-week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// parseField parse comma separated list of: * a a-b */n a-b/n a/n, return bits and whether it start with *,
+// like standard cron, a day field start with * is not restricted, e.g. */2, see dayMatch.
+func parseField(field string, b bounds) (bits uint64, star bool, err error) {
+	star = strings.HasPrefix(field, "*")
+
+	for _, part := range strings.Split(field, ",") {
+		var (
+			rangePart        = part
+			step      uint64 = 1
+		)
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			rangePart = part[:i]
+			step, err = strconv.ParseUint(part[i+1:], 10, 8)
+			if err != nil || step == 0 {
+				return 0, false, fmt.Errorf("invalid %s step %q", b.name, part)
+			}
+		}
+
+		var start, end uint
+		switch {
+		case rangePart == "*":
+			start, end = b.min, b.max
+		case strings.Contains(rangePart, "-"):
+			i := strings.Index(rangePart, "-")
+			if start, err = parseValue(rangePart[:i], b); err != nil {
+				return 0, false, err
+			}
+			if end, err = parseValue(rangePart[i+1:], b); err != nil {
+				return 0, false, err
+			}
+		default:
+			if start, err = parseValue(rangePart, b); err != nil {
+				return 0, false, err
+			}
+			end = start
+			// a/n means from a to max every n
+			if step > 1 {
+				end = b.max
+			}
+		}
+
+		if start > end {
+			return 0, false, fmt.Errorf("invalid %s range %q", b.name, part)
+		}
+
+		for v := start; v <= end; v += uint(step) {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, star, nil
+}
+
+func parseValue(value string, b bounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.ParseUint(value, 10, 8)
+	if err != nil || uint(v) < b.min || uint(v) > b.max {
+		return 0, fmt.Errorf("invalid %s value %q", b.name, value)
+	}
+
+	return uint(v), nil
+}
+
+type cronSchedule struct {
+	minute, hour, dayOfMonth, month, dayOfWeek uint64
+
+	dayOfMonthAny, dayOfWeekAny bool
+}
+
+// dayMatch follow standard cron: when both day-of-month and day-of-week restricted, match either,
+// otherwise match both
+func (s cronSchedule) dayMatch(t time.Time) bool {
+	var (
+		domMatch = s.dayOfMonth&(1<<uint(t.Day())) != 0
+		dowMatch = s.dayOfWeek&(1<<uint(t.Weekday())) != 0
+	)
+
+	if s.dayOfMonthAny || s.dayOfWeekAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func (s cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	// no match in 5 years means never, e.g. 30 feb
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatch(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+type everySchedule struct {
+	duration time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.duration)
+}
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	TestParseNextNotMatch = errors.New("next fire time not match spec")
+)
+
+func TestParse(t *testing.T) {
+
+	from := time.Date(2024, time.March, 15, 10, 30, 20, 0, time.UTC) // friday
+
+	for _, c := range []struct {
+		spec string
+		next time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.March, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.March, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * mon-fri", time.Date(2024, time.March, 18, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * 1-5", time.Date(2024, time.March, 18, 2, 0, 0, 0, time.UTC)},
+		{"5,10 11 * * *", time.Date(2024, time.March, 15, 11, 5, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		// day-of-month or day-of-week
+		{"0 0 20 * 6", time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		// day-of-month start with * and day-of-week: odd day and monday
+		{"0 0 */2 * 1", time.Date(2024, time.March, 25, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(time.Second * 90)},
+		// never
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		s, err := Parse(c.spec)
+		if err != nil {
+			t.Fatal(c.spec, err)
+		}
+		if next := s.Next(from); !next.Equal(c.next) {
+			t.Fatal(c.spec, next, TestParseNextNotMatch)
+		}
+	}
+
+	for _, spec := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@every 1ms",
+		"@every x",
+	} {
+		_, err := Parse(spec)
+		if err == nil {
+			t.Fatal(spec, "should be invalid")
+		}
+	}
+
+}
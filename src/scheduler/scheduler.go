@@ -0,0 +1,349 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/GanLuo96214/goroutine_pool/src/pool_manager"
+	"sync"
+	"time"
+)
+
+// Submitter is a pool accept tasks, e.g. pool.NewTaskPool
+type Submitter interface {
+	SubmitContext(ctx context.Context, task func()) error
+}
+
+// dropSubmitter is a Submitter tell when a queued task dropped without run, e.g. pool.NewTaskPool.
+// a dropped run is finished on drop, otherwise the job would be running forever.
+type dropSubmitter interface {
+	SubmitContextWithDrop(ctx context.Context, task func(), dropped func(err error)) error
+}
+
+func submitTo(p Submitter, ctx context.Context, task func(), dropped func(err error)) error {
+	if dp, ok := p.(dropSubmitter); ok {
+		return dp.SubmitContextWithDrop(ctx, task, dropped)
+	}
+	return p.SubmitContext(ctx, task)
+}
+
+// namedPool look up the pool in manager every fire,
+// so a pool released and added again with the name is used
+type namedPool struct {
+	manager *pool_manager.Manager
+	name    string
+}
+
+func (p namedPool) lookup() (Submitter, error) {
+	managed, ok := p.manager.Get(p.name)
+	if !ok {
+		return nil, pool_manager.ErrPoolNotFound
+	}
+	submitter, ok := managed.(Submitter)
+	if !ok {
+		return nil, addPoolNotSubmitter
+	}
+	return submitter, nil
+}
+
+func (p namedPool) SubmitContext(ctx context.Context, task func()) error {
+	return p.SubmitContextWithDrop(ctx, task, nil)
+}
+
+func (p namedPool) SubmitContextWithDrop(ctx context.Context, task func(), dropped func(err error)) error {
+	submitter, err := p.lookup()
+	if err != nil {
+		return err
+	}
+	return submitTo(submitter, ctx, task, dropped)
+}
+
+// OverlapPolicy decide what to do when a job fire but its previous run not finished.
+type OverlapPolicy int
+
+const (
+	OverlapSkip  OverlapPolicy = iota // skip this fire
+	OverlapQueue                      // run after previous run finished
+	OverlapAllow                      // run concurrently
+)
+
+type job struct {
+	name     string
+	schedule Schedule
+	pool     Submitter
+	task     func()
+	overlap  OverlapPolicy
+
+	next time.Time // zero means never
+
+	// guarded by scheduler.mutex
+	running bool
+	queued  uint64
+	removed bool
+}
+
+type scheduler struct {
+	mutex sync.Mutex
+	jobs  map[string]*job
+
+	manager *pool_manager.Manager // AddNamed look up pools in it
+	now     func() time.Time
+
+	wake chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	loopWaitGroup sync.WaitGroup
+	runWaitGroup  sync.WaitGroup // submitting and running jobs
+}
+
+var (
+	addNameAlreadyBeUsed = errors.New("job name already be used")
+	addPoolOrTaskIsNil   = errors.New("pool or task is nil")
+	addPoolNotSubmitter  = errors.New("pool can not accept tasks")
+	jobNameNotFound      = errors.New("job name not found")
+	schedulerIsShutdown  = errors.New("scheduler is shutdown")
+)
+
+func newScheduler(manager *pool_manager.Manager, now func() time.Time) *scheduler {
+	s := new(scheduler)
+	s.jobs = make(map[string]*job)
+	s.manager = manager
+	s.now = now
+	s.wake = make(chan struct{}, 1)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	s.loopWaitGroup.Add(1)
+	go s.loop()
+
+	return s
+}
+
+// Add schedule task by cron spec(see Parse), every fire submit task to pool.
+func (s *scheduler) Add(name string, spec string, pool Submitter, task func(), overlap OverlapPolicy) error {
+	if pool == nil || task == nil {
+		return addPoolOrTaskIsNil
+	}
+
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.ctx.Err() != nil {
+		return schedulerIsShutdown
+	}
+	if _, ok := s.jobs[name]; ok {
+		return addNameAlreadyBeUsed
+	}
+
+	s.jobs[name] = &job{
+		name:     name,
+		schedule: schedule,
+		pool:     pool,
+		task:     task,
+		overlap:  overlap,
+		next:     schedule.Next(s.now()),
+	}
+
+	s.notify()
+
+	return nil
+}
+
+// AddNamed same as Add, but submit task to the pool named poolName in the scheduler's pool_manager.
+// the pool is looked up every fire, a fire is skipped if the pool not found.
+func (s *scheduler) AddNamed(name string, spec string, poolName string, task func(), overlap OverlapPolicy) error {
+	p := namedPool{manager: s.manager, name: poolName}
+	if _, err := p.lookup(); err != nil {
+		return err
+	}
+
+	return s.Add(name, spec, p, task, overlap)
+}
+
+// Remove stop scheduling the job, running job is not affected.
+func (s *scheduler) Remove(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j, ok := s.jobs[name]
+	if !ok {
+		return jobNameNotFound
+	}
+	j.removed = true
+	delete(s.jobs, name)
+
+	return nil
+}
+
+// NextRuns report next n fire times of the job.
+func (s *scheduler) NextRuns(name string, n int) ([]time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	j, ok := s.jobs[name]
+	if !ok {
+		return nil, jobNameNotFound
+	}
+
+	runs := make([]time.Time, 0, n)
+	for next := j.next; len(runs) < n && !next.IsZero(); next = j.schedule.Next(next) {
+		runs = append(runs, next)
+	}
+
+	return runs, nil
+}
+
+// Names report all jobs' name.
+func (s *scheduler) Names() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (s *scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *scheduler) loop() {
+	defer s.loopWaitGroup.Done()
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		wait := s.fireDue(s.now())
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+		case <-s.wake:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// fireDue fire due jobs, return how long until next fire
+func (s *scheduler) fireDue(now time.Time) (wait time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	wait = time.Hour
+	if s.ctx.Err() != nil {
+		return wait
+	}
+
+	for _, j := range s.jobs {
+		if j.next.IsZero() {
+			continue
+		}
+
+		if !j.next.After(now) {
+			s.fire(j)
+
+			// next from the scheduled time so @every would not drift,
+			// but skip fires missed(e.g. system sleep)
+			j.next = j.schedule.Next(j.next)
+			if !j.next.IsZero() && !j.next.After(now) {
+				j.next = j.schedule.Next(now)
+			}
+			if j.next.IsZero() {
+				continue
+			}
+		}
+
+		if d := j.next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+
+	return wait
+}
+
+// must hold s.mutex
+func (s *scheduler) fire(j *job) {
+	if j.running {
+		switch j.overlap {
+		case OverlapSkip:
+			return
+		case OverlapQueue:
+			j.queued++
+			return
+		}
+	}
+
+	j.running = true
+	s.runWaitGroup.Add(1)
+	// submit may block when pool's queue is full, never block the loop
+	go s.submit(j)
+}
+
+func (s *scheduler) submit(j *job) {
+	err := submitTo(j.pool, s.ctx, func() {
+		defer s.finish(j)
+		j.task()
+	}, func(err error) {
+		// dropped by the pool, e.g. pool shutdown timeout
+		s.finish(j)
+	})
+	if err != nil {
+		s.finish(j)
+	}
+}
+
+// finish a run, start a queued run if any
+func (s *scheduler) finish(j *job) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	defer s.runWaitGroup.Done()
+
+	j.running = false
+	if j.queued > 0 && !j.removed && s.ctx.Err() == nil {
+		j.queued--
+		j.running = true
+		s.runWaitGroup.Add(1)
+		go s.submit(j)
+	}
+}
+
+// Shutdown stop firing jobs and wait submitted jobs finished, return ctx.Err() if ctx done first.
+func (s *scheduler) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	s.cancel()
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.loopWaitGroup.Wait()
+		s.runWaitGroup.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
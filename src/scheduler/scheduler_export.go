@@ -0,0 +1,20 @@
+package scheduler
+
+import (
+	"github.com/GanLuo96214/goroutine_pool/src/pool_manager"
+	"time"
+)
+
+// NewScheduler create a scheduler run jobs by cron spec in pools, it start immediately.
+// AddNamed look up pools in pool_manager.Default.
+func NewScheduler() *scheduler {
+	return newScheduler(pool_manager.Default, time.Now)
+}
+
+// NewSchedulerWithManager same as NewScheduler, but AddNamed look up pools in manager.
+func NewSchedulerWithManager(manager *pool_manager.Manager) *scheduler {
+	if manager == nil {
+		manager = pool_manager.Default
+	}
+	return newScheduler(manager, time.Now)
+}
@@ -0,0 +1,306 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"github.com/GanLuo96214/goroutine_pool/src/pool"
+	"github.com/GanLuo96214/goroutine_pool/src/pool_manager"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	TestSchedulerFiredCountNotMatch = errors.New("fired count not match overlap policy")
+)
+
+// goroutinePool run every task in a new goroutine
+type goroutinePool struct{}
+
+func (goroutinePool) SubmitContext(ctx context.Context, task func()) error {
+	go task()
+	return nil
+}
+
+func TestScheduler_Add(t *testing.T) {
+
+	s := NewScheduler()
+	defer s.Shutdown(context.Background())
+
+	err := s.Add("job", "@every 1s", goroutinePool{}, func() {}, OverlapAllow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Add("job", "@every 1s", goroutinePool{}, func() {}, OverlapAllow)
+	if err != addNameAlreadyBeUsed {
+		t.Fatal(err)
+	}
+	err = s.Add("nil", "@every 1s", nil, func() {}, OverlapAllow)
+	if err != addPoolOrTaskIsNil {
+		t.Fatal(err)
+	}
+	err = s.Add("invalid", "* * *", goroutinePool{}, func() {}, OverlapAllow)
+	if err == nil {
+		t.Fatal("invalid spec should be error")
+	}
+
+	runs, err := s.NextRuns("job", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 || runs[1].Sub(runs[0]) != time.Second || runs[2].Sub(runs[1]) != time.Second {
+		t.Fatal(runs)
+	}
+
+	err = s.Remove("job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.NextRuns("job", 1)
+	if err != jobNameNotFound {
+		t.Fatal(err)
+	}
+
+}
+
+// fakeClock only move when advanced
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// advance move the clock and wake the scheduler to fire due jobs
+func (c *fakeClock) advance(s *scheduler, d time.Duration) {
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+	c.mutex.Unlock()
+	s.notify()
+}
+
+func TestScheduler_overlap(t *testing.T) {
+
+	var (
+		clock   = newFakeClock()
+		started = make(chan string, 10)
+		release = make(chan struct{})
+	)
+
+	s := newScheduler(pool_manager.NewManager(), clock.Now)
+
+	for name, overlap := range map[string]OverlapPolicy{
+		"skip":  OverlapSkip,
+		"queue": OverlapQueue,
+		"allow": OverlapAllow,
+	} {
+		name := name
+		err := s.Add(name, "@every 1s", goroutinePool{}, func() {
+			started <- name
+			<-release
+		}, overlap)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	receive := func() string {
+		select {
+		case name := <-started:
+			return name
+		case <-time.After(time.Second * 3):
+			t.Fatal(TestSchedulerFiredCountNotMatch)
+			return ""
+		}
+	}
+
+	// first fire start every job
+	clock.advance(s, time.Second)
+	counts := map[string]int{}
+	for i := 0; i < 3; i++ {
+		counts[receive()]++
+	}
+	if counts["skip"] != 1 || counts["queue"] != 1 || counts["allow"] != 1 {
+		t.Fatal(counts, TestSchedulerFiredCountNotMatch)
+	}
+
+	// fired 2 more times while the first run blocked, only allow start
+	for i := 0; i < 2; i++ {
+		clock.advance(s, time.Second)
+		if name := receive(); name != "allow" {
+			t.Fatal(name, TestSchedulerFiredCountNotMatch)
+		}
+	}
+	// fires are done under the lock before the allow run started
+	s.mutex.Lock()
+	queued := s.jobs["queue"].queued
+	s.mutex.Unlock()
+	if queued != 2 {
+		t.Fatal(queued, TestSchedulerFiredCountNotMatch)
+	}
+
+	err := s.Remove("allow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// queued runs run one by one after release
+	close(release)
+	for i := 0; i < 2; i++ {
+		if name := receive(); name != "queue" {
+			t.Fatal(name, TestSchedulerFiredCountNotMatch)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = s.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case name := <-started:
+		t.Fatal(name, TestSchedulerFiredCountNotMatch)
+	default:
+	}
+
+	err = s.Add("after shutdown", "@every 1s", goroutinePool{}, func() {}, OverlapAllow)
+	if err != schedulerIsShutdown {
+		t.Fatal(err)
+	}
+
+}
+
+var (
+	TestSchedulerJobNotReleased = errors.New("job dropped by pool should be released")
+)
+
+func TestScheduler_dropped(t *testing.T) {
+
+	// no container, the fire stay queued
+	p, err := pool.NewTaskPool(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := newFakeClock()
+	s := newScheduler(pool_manager.NewManager(), clock.Now)
+
+	err = s.Add("job", "@every 1s", p, func() {}, OverlapSkip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.advance(s, time.Second)
+
+	deadline := time.Now().Add(time.Second * 3)
+	for p.GetQueueLength() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal(TestSchedulerFiredCountNotMatch)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// queued fire dropped
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	err = p.Shutdown(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+	s.mutex.Lock()
+	running := s.jobs["job"].running
+	s.mutex.Unlock()
+	if running {
+		t.Fatal(TestSchedulerJobNotReleased)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = s.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(TestSchedulerJobNotReleased, err)
+	}
+
+}
+
+func TestScheduler_AddNamed(t *testing.T) {
+
+	p, err := pool.NewTaskPool(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := pool_manager.NewManager()
+	err = m.Add("task pool", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Release("task pool")
+
+	clock := newFakeClock()
+	s := newScheduler(m, clock.Now)
+	defer s.Shutdown(context.Background())
+
+	err = s.AddNamed("missing", "@every 1s", "not added", func() {}, OverlapSkip)
+	if err != pool_manager.ErrPoolNotFound {
+		t.Fatal(err)
+	}
+
+	fired := make(chan struct{}, 1)
+	err = s.AddNamed("job", "@every 1s", "task pool", func() {
+		select {
+		case fired <- struct{}{}:
+		default:
+		}
+	}, OverlapSkip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock.advance(s, time.Second)
+	select {
+	case <-fired:
+	case <-time.After(time.Second * 3):
+		t.Fatal(TestSchedulerFiredCountNotMatch)
+	}
+
+}
+
+func TestScheduler_taskPool(t *testing.T) {
+
+	p, err := pool.NewTaskPool(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Shutdown(context.Background())
+
+	s := NewScheduler()
+	defer s.Shutdown(context.Background())
+
+	fired := make(chan struct{}, 1)
+	err = s.Add("task pool", "@every 1s", p, func() {
+		select {
+		case fired <- struct{}{}:
+		default:
+		}
+	}, OverlapSkip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second * 3):
+		t.Fatal(TestSchedulerFiredCountNotMatch)
+	}
+
+}